@@ -0,0 +1,103 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-releases/cmd/goreleaser/internal"
+)
+
+// apkoImages builds the images of the distributions opting in to apko: their
+// package is compiled by melange from the generated sources, and apko
+// assembles it with the Wolfi packages into multi-arch images, published with
+// their SBOMs, or written next to their apko configuration without -publish.
+func apkoImages(args []string) {
+	fs := flag.NewFlagSet("apko-images", flag.ExitOnError)
+	distsFlag := fs.String("d", "", "Collector distributions(s) to build the images of, comma-separated")
+	prefixes := fs.String("image-prefixes", "", imagePrefixesUsage)
+	ecrPublic := fs.String("ecr-public-alias", "", "Also publish the images to Amazon ECR Public, under this registry alias")
+	skipLatest := fs.Bool("skip-latest", false, "Leave the latest image tags of every distribution out")
+	signingKey := fs.String("signing-key", "melange.rsa", "Key signing the packages built by melange, generated if missing")
+	publish := fs.Bool("publish", false, "Publish the images to the registries instead of writing them to tarballs in _apko")
+	setupLogging := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLogging()
+
+	if len(*distsFlag) == 0 {
+		logger.Fatal("no distributions to build the images of")
+	}
+	dists, err := internal.LoadDistributions(strings.Split(*distsFlag, ","))
+	if err != nil {
+		logger.Fatal("failed to load the distributions", "error", err)
+	}
+	setImagePrefixes(*prefixes)
+	if *ecrPublic != "" {
+		internal.ImagePrefixes = append(internal.ImagePrefixes, path.Join(internal.ECRPublicRegistry, *ecrPublic))
+	}
+	internal.SkipLatest = *skipLatest
+	dists, err = internal.WriteApkoConfigs(dists)
+	if err != nil {
+		logger.Fatal("failed to write the apko configurations", "error", err)
+	}
+	if len(dists) == 0 {
+		logger.Info("no distribution builds its images with apko")
+		return
+	}
+
+	if _, err := os.Stat(*signingKey); os.IsNotExist(err) {
+		run("melange", "keygen", *signingKey)
+	}
+	releases, err := internal.ReleaseTags()
+	if err != nil {
+		logger.Fatal("failed to list the releases", "error", err)
+	}
+	for _, dist := range dists {
+		if _, err := os.Stat(path.Join("distributions", dist.ID, "_build")); err != nil {
+			logger.Fatal("sources not found, run `make generate-sources` first", "distribution", dist.ID, "error", err)
+		}
+		dir := internal.ApkoDir(dist)
+		archs := strings.Join(internal.ApkoImageArchitectures(dist), ",")
+		run("melange", "build", path.Join(dir, "melange.yaml"),
+			"--source-dir", ".",
+			"--out-dir", path.Join(dir, "packages"),
+			"--signing-key", *signingKey,
+			"--arch", archs)
+
+		for _, image := range internal.ApkoImages(dist) {
+			cfg := internal.ApkoConfigFile(dist, image)
+			tags := internal.ApkoImageTags(internal.ImagePrefixes, dist, image, releases)
+			apkoArgs := append([]string{"publish", cfg}, tags...)
+			if !*publish {
+				apkoArgs = []string{"build", cfg, tags[0], strings.TrimSuffix(cfg, ".yaml") + ".tar"}
+			}
+			run("apko", append(apkoArgs, "--keyring-append", *signingKey+".pub", "--arch", archs)...)
+			logger.Info("built the apko image", "distribution", dist.ID, "published", *publish, "tags", strings.Join(tags, ","))
+		}
+	}
+}
+
+func run(name string, args ...string) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		logger.Fatal("command failed", "command", name+" "+strings.Join(args, " "), "error", err)
+	}
+}
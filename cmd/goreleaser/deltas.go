@@ -0,0 +1,72 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-releases/cmd/goreleaser/internal"
+)
+
+// deltas writes the binary patches upgrading the distributions from the
+// previous release to the new one, whose archives are downloaded first with
+// -previous-release. The distributions without archives in the previous
+// release get no patches.
+func deltas(args []string) {
+	fs := flag.NewFlagSet("deltas", flag.ExitOnError)
+	dists := fs.String("d", "", "Collector distributions(s) to patch, comma-separated")
+	oldDir := fs.String("old", "", "Directory holding the archives of the previous release")
+	newDir := fs.String("new", "dist", "Directory holding the archives of the new release")
+	previous := fs.String("previous-release", "", "Tag of the previous release, whose archives are downloaded to the -old directory with the gh CLI")
+	setupLogging := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLogging()
+
+	if len(*dists) == 0 {
+		logger.Fatal("no distributions to patch")
+	}
+	if len(*oldDir) == 0 {
+		logger.Fatal("the -old archives directory is required")
+	}
+
+	distributions, err := internal.LoadDistributions(strings.Split(*dists, ","))
+	if err != nil {
+		logger.Fatal("failed to load the distributions", "error", err)
+	}
+	if *previous != "" {
+		if err := os.MkdirAll(*oldDir, 0o755); err != nil {
+			logger.Fatal("failed to create the archives directory", "error", err)
+		}
+		for _, dist := range distributions {
+			found, err := internal.DownloadReleaseArchives(*previous, *oldDir, dist)
+			if err != nil {
+				logger.Fatal("failed to download the previous release", "error", err)
+			}
+			if !found {
+				logger.Warn("no archives in the previous release, skipping the distribution", "distribution", dist.ID, "release", *previous)
+			}
+		}
+	}
+	patches, err := internal.WriteDeltas(*oldDir, *newDir, distributions)
+	if err != nil {
+		logger.Fatal("failed to generate the delta patches", "error", err)
+	}
+	for _, p := range patches {
+		logger.Debug("generated a delta patch", "distribution", p.Dist, "platform", p.Platform, "from", p.OldVersion, "to", p.NewVersion, "path", p.Path)
+	}
+	logger.Info("generated the delta patches", "patches", len(patches), "directory", internal.DeltaDir)
+}
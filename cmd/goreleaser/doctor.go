@@ -0,0 +1,37 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/open-telemetry/opentelemetry-collector-releases/cmd/goreleaser/internal"
+)
+
+// doctor checks that the local environment has everything the release
+// pipeline needs, printing how to fix what's missing.
+func doctor(args []string) {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	prefixes := fs.String("image-prefixes", "", imagePrefixesUsage)
+	setupLogging := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLogging()
+	setImagePrefixes(*prefixes)
+
+	if !internal.RunDoctor(os.Stdout, internal.DoctorChecks(internal.ImagePrefixes)) {
+		os.Exit(1)
+	}
+}
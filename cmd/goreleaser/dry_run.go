@@ -0,0 +1,101 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/open-telemetry/opentelemetry-collector-releases/cmd/goreleaser/internal"
+)
+
+// dryRun generates the goreleaser configuration for the selected
+// distributions and targets in a temporary directory, and runs a snapshot
+// release with it. Nothing is published.
+func dryRun(args []string) {
+	fs := flag.NewFlagSet("dry-run", flag.ExitOnError)
+	distsFlag := fs.String("d", "", "Collector distributions(s) to release, comma-separated")
+	targetsFlag := fs.String("targets", "", "goreleaser targets to build, comma-separated, e.g. linux_amd64_v1,linux_arm64 (default: all)")
+	goreleaser := fs.String("goreleaser", "goreleaser", "Path to the goreleaser binary")
+	distDir := fs.String("dist", "", "goreleaser's output directory (default: goreleaser's dist)")
+	clean := fs.Bool("clean", true, "Remove goreleaser's output directory before the release")
+	prefixes := fs.String("image-prefixes", "", imagePrefixesUsage)
+	setupLogging := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLogging()
+
+	if len(*distsFlag) == 0 {
+		logger.Fatal("no distributions to release")
+	}
+	dists, err := internal.LoadDistributions(strings.Split(*distsFlag, ","))
+	if err != nil {
+		logger.Fatal("failed to load the distributions", "error", err)
+	}
+	for _, dist := range dists {
+		if _, err := os.Stat(path.Join("distributions", dist.ID, "_build")); err != nil {
+			logger.Fatal("sources not found, run `make generate-sources` first", "distribution", dist.ID, "error", err)
+		}
+	}
+
+	internal.DistDir = *distDir
+	setImagePrefixes(*prefixes)
+	releases, err := internal.ReleaseTags()
+	if err != nil {
+		logger.Fatal("failed to list the releases", "error", err)
+	}
+	project := internal.Generate(internal.ImagePrefixes, dists, releases)
+	if len(*targetsFlag) > 0 {
+		internal.RestrictTargets(&project, strings.Split(*targetsFlag, ","))
+	}
+
+	dir, err := os.MkdirTemp("", "otelcol-dry-run")
+	if err != nil {
+		logger.Fatal("failed to create a temporary directory", "error", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := filepath.Join(dir, ".goreleaser.yaml")
+	f, err := os.Create(cfg)
+	if err != nil {
+		logger.Fatal("failed to create the goreleaser configuration", "error", err)
+	}
+	if err := yaml.NewEncoder(f).Encode(&project); err != nil {
+		logger.Fatal("failed to write the goreleaser configuration", "error", err)
+	}
+	if err := f.Close(); err != nil {
+		logger.Fatal("failed to write the goreleaser configuration", "error", err)
+	}
+
+	logger.Info("running a snapshot release", "config", cfg)
+	// Keyless signing needs the OIDC identity of the release workflow.
+	goreleaserArgs := []string{"release", "--snapshot", "--skip-sign", "--config", cfg}
+	if *clean {
+		goreleaserArgs = append(goreleaserArgs, "--clean")
+	}
+	cmd := exec.Command(*goreleaser, goreleaserArgs...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		// os.Exit would skip removing the temporary directory.
+		os.RemoveAll(dir)
+		logger.Fatal("the snapshot release failed", "error", err)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-releases/cmd/goreleaser/internal"
+)
+
+// imageDigests lists the image manifests pushed by the release published by
+// goreleaser and adds the list to its checksums file. With -publish, the
+// checksums file is signed again with cosign, like goreleaser signs it, and
+// both are uploaded to the GitHub release with the GitHub CLI.
+func imageDigests(args []string) {
+	fs := flag.NewFlagSet("image-digests", flag.ExitOnError)
+	dists := fs.String("d", "", "Collector distributions(s) to list the images of, comma-separated")
+	distDir := fs.String("dist", "dist", "goreleaser's dist directory, holding the published release")
+	tag := fs.String("tag", "", "Tag of the GitHub release to upload the files to")
+	publish := fs.Bool("publish", false, "Sign the checksums file and upload the files to the GitHub release")
+	setupLogging := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLogging()
+
+	if len(*dists) == 0 {
+		logger.Fatal("no distributions to list the images of")
+	}
+	if *publish && *tag == "" {
+		logger.Fatal("no release to upload the files to")
+	}
+	distributions, err := internal.LoadDistributions(strings.Split(*dists, ","))
+	if err != nil {
+		logger.Fatal("failed to load the distributions", "error", err)
+	}
+	digests, err := internal.WriteImageDigests(*distDir, distributions)
+	if err != nil {
+		logger.Fatal("failed to list the image digests", "error", err)
+	}
+	if digests.File == "" {
+		logger.Info("no image was pushed by the release", "dist", *distDir)
+		return
+	}
+	logger.Info("added the image digests to the checksums file", "output", digests.File, "checksums", digests.Checksums)
+	if !*publish {
+		return
+	}
+
+	signature, certificate := digests.Checksums+".sig", digests.Checksums+".pem"
+	run("cosign", "sign-blob", "--yes", "--output-signature="+signature, "--output-certificate="+certificate, digests.Checksums)
+	run("gh", "release", "upload", *tag, "--clobber", digests.File, digests.Checksums, signature, certificate)
+	logger.Info("published the image digests", "release", *tag)
+}
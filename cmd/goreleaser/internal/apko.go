@@ -0,0 +1,326 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// WolfiRepository is the APK repository of the Wolfi packages the apko
+	// images are made of.
+	WolfiRepository = "https://packages.wolfi.dev/os"
+	// WolfiKeyring is the key signing the Wolfi packages.
+	WolfiKeyring = "https://packages.wolfi.dev/os/wolfi-signing.rsa.pub"
+
+	apkoHeader = "# Code generated by cmd/goreleaser from the distribution manifest. DO NOT EDIT.\n# Regenerate it with `make apko-images`.\n"
+)
+
+// ApkoArchitectures are the architectures Wolfi packages are published for.
+var ApkoArchitectures = []string{"amd64", "arm64"}
+
+// ApkoDir holds the melange and apko configurations of the distribution,
+// and the packages melange builds.
+func ApkoDir(dist Distribution) string {
+	return path.Join("distributions", dist.ID, "_apko")
+}
+
+// MelangeConfig is the part of a melange configuration the generator writes.
+// https://github.com/chainguard-dev/melange/blob/main/docs/BUILD-FILE.md
+type MelangeConfig struct {
+	Package     MelangePackage     `yaml:"package"`
+	Environment MelangeEnvironment `yaml:"environment"`
+	Pipeline    []MelangeStep      `yaml:"pipeline"`
+}
+
+// MelangePackage describes the APK package melange builds.
+type MelangePackage struct {
+	Name        string             `yaml:"name"`
+	Version     string             `yaml:"version"`
+	Epoch       int                `yaml:"epoch"`
+	Description string             `yaml:"description"`
+	Copyright   []MelangeCopyright `yaml:"copyright"`
+}
+
+// MelangeCopyright is the license of a package.
+type MelangeCopyright struct {
+	License string `yaml:"license"`
+}
+
+// MelangeEnvironment is the build environment of a package.
+type MelangeEnvironment struct {
+	Contents ApkoContents `yaml:"contents"`
+}
+
+// MelangeStep is a step of the build pipeline of a package, either a
+// built-in pipeline or a shell script.
+type MelangeStep struct {
+	Uses string            `yaml:"uses,omitempty"`
+	With map[string]string `yaml:"with,omitempty"`
+	Runs string            `yaml:"runs,omitempty"`
+}
+
+// ApkoConfig is the part of an apko image configuration the generator
+// writes.
+// https://github.com/chainguard-dev/apko/blob/main/docs/apko_file.md
+type ApkoConfig struct {
+	Contents    ApkoContents      `yaml:"contents"`
+	Accounts    ApkoAccounts      `yaml:"accounts"`
+	Entrypoint  ApkoEntrypoint    `yaml:"entrypoint"`
+	Cmd         string            `yaml:"cmd"`
+	Paths       []ApkoPath        `yaml:"paths"`
+	Archs       []string          `yaml:"archs"`
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+// ApkoContents are the APK repositories, keys and packages to install.
+type ApkoContents struct {
+	Repositories []string `yaml:"repositories"`
+	Keyring      []string `yaml:"keyring"`
+	Packages     []string `yaml:"packages"`
+}
+
+// ApkoAccounts are the users and groups of the image.
+type ApkoAccounts struct {
+	Groups []ApkoGroup `yaml:"groups"`
+	Users  []ApkoUser  `yaml:"users"`
+	RunAs  string      `yaml:"run-as"`
+}
+
+// ApkoGroup is a group of the image.
+type ApkoGroup struct {
+	Groupname string `yaml:"groupname"`
+	GID       int    `yaml:"gid"`
+}
+
+// ApkoUser is a user of the image.
+type ApkoUser struct {
+	Username string `yaml:"username"`
+	UID      int    `yaml:"uid"`
+	GID      int    `yaml:"gid"`
+}
+
+// ApkoEntrypoint is the entrypoint of the image.
+type ApkoEntrypoint struct {
+	Command string `yaml:"command"`
+}
+
+// ApkoPath is a path created in the image.
+type ApkoPath struct {
+	Path        string `yaml:"path"`
+	Type        string `yaml:"type"`
+	UID         int    `yaml:"uid"`
+	GID         int    `yaml:"gid"`
+	Permissions int    `yaml:"permissions"`
+}
+
+// ApkoImageArchitectures are the architectures of the distribution's apko
+// images.
+func ApkoImageArchitectures(dist Distribution) (r []string) {
+	for _, p := range imagePlatforms(dist) {
+		if contains(ApkoArchitectures, p.arch) && !contains(r, p.arch) {
+			r = append(r, p.arch)
+		}
+	}
+	return
+}
+
+// Melange configures the APK package of a distribution, compiled by melange
+// from its generated sources, with its default config, the configs of its
+// config variants and its bundled configs. The sources are
+// relative to the repository, melange's source directory. The binary is
+// stamped with the version, commit and date of the release like the others.
+func Melange(dist Distribution, commit ReleaseCommit) (MelangeConfig, error) {
+	version := strings.TrimPrefix(dist.Dist.Version, "v")
+	ldflags, err := commit.Ldflags()
+	if err != nil {
+		return MelangeConfig{}, err
+	}
+	build := map[string]string{
+		"packages": ".",
+		"modroot":  path.Join("distributions", dist.ID, "_build"),
+		"output":   dist.BinaryName(),
+		"ldflags":  strings.Join(append([]string{"-s", "-w"}, ldflags...), " "),
+	}
+	if tags := dist.BuildTags(); len(tags) > 0 {
+		build["tags"] = strings.Join(tags, ",")
+	}
+	install := fmt.Sprintf(`install -Dm644 %s "${{targets.destdir}}/etc/%s/config.yaml"`, path.Join("configs", fmt.Sprintf("%s.yaml", dist.ID)), dist.ID)
+	configs := append([]string{}, dist.Release.ConfigVariants...)
+	for _, name := range dist.Release.Docker.BundledConfigs {
+		if !contains(configs, name) {
+			configs = append(configs, name)
+		}
+	}
+	for _, name := range configs {
+		install += fmt.Sprintf("\n"+`install -Dm644 %s "${{targets.destdir}}/etc/%s/%s.yaml"`, configVariantPath(dist, name), dist.ID, name)
+	}
+	return MelangeConfig{
+		Package: MelangePackage{
+			Name:        dist.BinaryName(),
+			Version:     version,
+			Description: dist.Description(),
+			Copyright:   []MelangeCopyright{{License: dist.License()}},
+		},
+		Environment: MelangeEnvironment{
+			Contents: ApkoContents{
+				Repositories: []string{WolfiRepository},
+				Keyring:      []string{WolfiKeyring},
+				Packages:     []string{"build-base", "busybox", "ca-certificates-bundle", "go"},
+			},
+		},
+		Pipeline: []MelangeStep{
+			{Uses: "go/build", With: build},
+			{Runs: install},
+		},
+	}, nil
+}
+
+// ApkoImages are the images of a distribution built by apko: the default one,
+// named "", and one per config variant.
+func ApkoImages(dist Distribution) []string {
+	return append([]string{""}, dist.Release.ConfigVariants...)
+}
+
+// ApkoConfigFile is the apko configuration of an image of the distribution,
+// apko.yaml for the default image and apko-<variant>.yaml for a config
+// variant.
+func ApkoConfigFile(dist Distribution, image string) string {
+	if image == "" {
+		return path.Join(ApkoDir(dist), "apko.yaml")
+	}
+	return path.Join(ApkoDir(dist), fmt.Sprintf("apko-%s.yaml", image))
+}
+
+// Apko configures an image of a distribution, made of the Wolfi base layout,
+// the CA certificates and the package built by melange, read from the local
+// repository of packagesDir. The images of the config variants run with the
+// config of their variant.
+func Apko(dist Distribution, image, packagesDir string) ApkoConfig {
+	accounts := ApkoAccounts{
+		Users: []ApkoUser{{Username: "otel", UID: dist.UID(), GID: dist.GID()}},
+		RunAs: fmt.Sprintf("%d:%d", dist.UID(), dist.GID()),
+	}
+	// The root group is part of the Wolfi base layout.
+	if dist.GID() != 0 {
+		accounts.Groups = []ApkoGroup{{Groupname: "otel", GID: dist.GID()}}
+	}
+	configPath := path.Join("/etc", dist.ID, "config.yaml")
+	if image != "" {
+		configPath = path.Join("/etc", dist.ID, image+".yaml")
+	}
+	return ApkoConfig{
+		Contents: ApkoContents{
+			Repositories: []string{WolfiRepository, "@local " + packagesDir},
+			Keyring:      []string{WolfiKeyring},
+			Packages:     []string{"wolfi-baselayout", "ca-certificates-bundle", dist.BinaryName() + "@local"},
+		},
+		Accounts:   accounts,
+		Entrypoint: ApkoEntrypoint{Command: path.Join("/usr/bin", dist.BinaryName())},
+		Cmd:        "--config " + configPath,
+		// State directory, holding the default directory of the file_storage
+		// extension, which doesn't create it, writable by the collector user
+		// and group when the root filesystem is read-only, like in the other
+		// images.
+		Paths: []ApkoPath{
+			{Path: "/var/lib/otelcol", Type: "directory", UID: dist.UID(), GID: dist.GID(), Permissions: 0o775},
+			{Path: "/var/lib/otelcol/file_storage", Type: "directory", UID: dist.UID(), GID: dist.GID(), Permissions: 0o775},
+		},
+		Archs: ApkoImageArchitectures(dist),
+		Annotations: map[string]string{
+			"org.opencontainers.image.title":         dist.DisplayName(),
+			"org.opencontainers.image.description":   dist.Description(),
+			"org.opencontainers.image.url":           dist.Homepage(),
+			"org.opencontainers.image.licenses":      dist.License(),
+			"org.opencontainers.image.vendor":        dist.Vendor(),
+			"org.opencontainers.image.documentation": dist.Documentation(),
+			"org.opencontainers.image.version":       strings.TrimPrefix(dist.Dist.Version, "v"),
+		},
+	}
+}
+
+// ApkoImageTags are the tags apko publishes an image of a distribution to,
+// its version, latest unless skipped and its floating tags under every prefix,
+// suffixed with the config variant like the other images. Prereleases don't
+// move the floating tags, and the mutable tags are only moved by the latest
+// release of their line among the release tags, so that a patch of an older
+// line doesn't move latest nor the major tag.
+func ApkoImageTags(imagePrefixes []string, dist Distribution, image string, releases []string) (r []string) {
+	variant := ImageVariant{Suffix: image}
+	version := strings.TrimPrefix(dist.Dist.Version, "v")
+	tags := []string{variant.tag(version)}
+	if !dist.SkipsLatest() && LatestInLine("v"+version, "", releases) {
+		tags = append(tags, variant.tag("latest"))
+	}
+	if v := "v" + version; semver.IsValid(v) && semver.Prerelease(v) == "" {
+		for _, line := range []string{semver.MajorMinor(v), semver.Major(v)} {
+			if LatestInLine(v, line, releases) {
+				tags = append(tags, variant.tag(strings.TrimPrefix(line, "v")))
+			}
+		}
+	}
+	for _, prefix := range imagePrefixes {
+		for _, tag := range tags {
+			r = append(r, fmt.Sprintf("%s/%s:%s", prefix, imageName(dist), tag))
+		}
+	}
+	return
+}
+
+// WriteApkoConfigs writes the melange and apko configurations of the
+// distributions opting in to ApkoDir, and returns the distributions written.
+func WriteApkoConfigs(dists []Distribution) ([]Distribution, error) {
+	written, err := forEach(dists, func(dist Distribution) (bool, error) {
+		if !dist.Release.Docker.Apko {
+			return false, nil
+		}
+		dir := ApkoDir(dist)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return false, err
+		}
+		commit, err := HeadCommit(dist.Dist.Version)
+		if err != nil {
+			return false, err
+		}
+		melange, err := Melange(dist, commit)
+		if err != nil {
+			return false, fmt.Errorf("failed to configure the melange build of distribution %q: %w", dist.ID, err)
+		}
+		if err := writeYAML(path.Join(dir, "melange.yaml"), apkoHeader, melange); err != nil {
+			return false, fmt.Errorf("failed to write the melange configuration of distribution %q: %w", dist.ID, err)
+		}
+		for _, image := range ApkoImages(dist) {
+			if err := writeYAML(ApkoConfigFile(dist, image), apkoHeader, Apko(dist, image, path.Join(dir, "packages"))); err != nil {
+				return false, fmt.Errorf("failed to write the apko configuration of distribution %q: %w", dist.ID, err)
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var r []Distribution
+	for i, dist := range dists {
+		if written[i] {
+			r = append(r, dist)
+		}
+	}
+	return r, nil
+}
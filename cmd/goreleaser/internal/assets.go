@@ -0,0 +1,106 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+// AssetVariantDir is the directory holding the downloaded assets and the
+// Dockerfile of an asset variant, relative to the repository.
+func AssetVariantDir(dist Distribution, variant AssetVariant) string {
+	return path.Join("distributions", dist.ID, fmt.Sprintf("_assets-%s", variant.Name))
+}
+
+// assetPath is where an asset is downloaded, relative to the repository.
+func assetPath(dist Distribution, variant AssetVariant, asset Asset) string {
+	return path.Join(AssetVariantDir(dist, variant), path.Base(asset.URL))
+}
+
+// WriteImageAssets downloads the assets of every asset variant of the given
+// distributions, verifying their checksums, and writes the variants'
+// Dockerfiles, which copy the assets into the distribution's image.
+func WriteImageAssets(dists []Distribution) error {
+	_, err := forEach(dists, func(dist Distribution) (struct{}, error) {
+		for _, variant := range dist.Release.Docker.AssetVariants {
+			if err := writeAssetVariant(dist, variant); err != nil {
+				return struct{}{}, fmt.Errorf("failed to prepare the image variant %q of distribution %q: %w", variant.Name, dist.ID, err)
+			}
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+func writeAssetVariant(dist Distribution, variant AssetVariant) error {
+	if err := os.MkdirAll(AssetVariantDir(dist, variant), 0o755); err != nil {
+		return err
+	}
+
+	dockerfile, err := os.ReadFile(dist.ImageDockerfile())
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.Write(dockerfile)
+	if !bytes.HasSuffix(dockerfile, []byte("\n")) {
+		buf.WriteString("\n")
+	}
+	for _, asset := range variant.Assets {
+		if err := downloadAsset(asset, assetPath(dist, variant, asset)); err != nil {
+			return err
+		}
+		fmt.Fprintf(&buf, "COPY --chmod=644 %s %s\n", assetPath(dist, variant, asset), asset.Path)
+	}
+	return os.WriteFile(path.Join(AssetVariantDir(dist, variant), "Dockerfile"), buf.Bytes(), 0o644)
+}
+
+// downloadAsset downloads the asset to dst, unless a file with the expected
+// checksum is already there.
+func downloadAsset(asset Asset, dst string) error {
+	if b, err := os.ReadFile(dst); err == nil && checksum(b) == strings.ToLower(asset.SHA256) {
+		return nil
+	}
+
+	resp, err := http.Get(asset.URL)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", asset.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: %s", asset.URL, resp.Status)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", asset.URL, err)
+	}
+	if sum := checksum(b); sum != strings.ToLower(asset.SHA256) {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", asset.URL, asset.SHA256, sum)
+	}
+	return os.WriteFile(dst, b, 0o644)
+}
+
+func checksum(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,128 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	coreModulePrefix    = "go.opentelemetry.io/collector/"
+	contribModulePrefix = "github.com/open-telemetry/opentelemetry-collector-contrib/"
+)
+
+// AuditComponents checks the component versions pinned by the manifests of
+// the given distributions. It fails when a distribution mixes versions of the
+// core or contrib components, when they don't belong to the same collector
+// release, or when a version was retracted by its module.
+func AuditComponents(dists []Distribution) error {
+	results, err := forEach(dists, auditDistribution)
+	if err != nil {
+		return err
+	}
+	var problems []string
+	for _, r := range results {
+		problems = append(problems, r...)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("component audit failed:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+func auditDistribution(dist Distribution) ([]string, error) {
+	components, err := manifestComponents(dist)
+	if err != nil {
+		return nil, err
+	}
+	var problems []string
+	for _, problem := range versionSkew(dist, components) {
+		problems = append(problems, fmt.Sprintf("%s: %s", dist.ID, problem))
+	}
+	retracted, err := retractedVersions(components)
+	if err != nil {
+		return nil, err
+	}
+	for _, problem := range retracted {
+		problems = append(problems, fmt.Sprintf("%s: %s", dist.ID, problem))
+	}
+	return problems, nil
+}
+
+// versionSkew reports the core and contrib components whose versions differ
+// from the other ones of their repository, or from the collector version of
+// the distribution. Core and contrib components must share their minor
+// version.
+func versionSkew(dist Distribution, components []Component) []string {
+	var problems []string
+	versions := map[string]string{}
+	check := func(repository, prefix, expected, reference string) {
+		for _, c := range components {
+			if !strings.HasPrefix(c.Module, prefix) {
+				continue
+			}
+			if expected == "" {
+				expected = c.Version
+				reference = fmt.Sprintf("the version of the other %s components", repository)
+			}
+			if c.Version != expected {
+				problems = append(problems, fmt.Sprintf("%s is pinned to %s instead of %s, %s", c.Module, c.Version, expected, reference))
+			}
+		}
+		versions[repository] = expected
+	}
+
+	coreVersion := ""
+	if dist.Dist.OtelColVersion != "" {
+		coreVersion = "v" + strings.TrimPrefix(dist.Dist.OtelColVersion, "v")
+	}
+	check("core", coreModulePrefix, coreVersion, "the otelcol_version of the distribution")
+	check("contrib", contribModulePrefix, "", "")
+
+	if core, contrib := versions["core"], versions["contrib"]; core != "" && contrib != "" && minorVersion(core) != minorVersion(contrib) {
+		problems = append(problems, fmt.Sprintf("the core components are at %s, but the contrib components are at %s", core, contrib))
+	}
+	return problems
+}
+
+// minorVersion returns the major and minor components of a version, such as
+// "v0.89" for "v0.89.1".
+func minorVersion(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	return strings.Join(parts[:2], ".")
+}
+
+// retractedVersions reports the components pinned to a version retracted by
+// its module, or that can't be resolved, as listed by the Go module proxy.
+func retractedVersions(components []Component) ([]string, error) {
+	modules, err := listModules(components)
+	if err != nil {
+		return nil, err
+	}
+	var problems []string
+	for _, module := range modules {
+		switch {
+		case module.err() != "":
+			problems = append(problems, fmt.Sprintf("%s@%s can't be resolved: %s", module.Path, module.Version, module.err()))
+		case len(module.Retracted) > 0:
+			problems = append(problems, fmt.Sprintf("%s@%s is retracted: %s", module.Path, module.Version, strings.Join(module.Retracted, "; ")))
+		}
+	}
+	return problems, nil
+}
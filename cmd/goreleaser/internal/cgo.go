@@ -0,0 +1,39 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+// CgoCompilers are the C cross-compilers of the targets cgo distributions are
+// built for, as <goos>/<goarch>, named after the Ubuntu packages providing
+// them. The other targets are left out of cgo distributions.
+var CgoCompilers = map[string]string{
+	"linux/386":     "i686-linux-gnu-gcc",
+	"linux/amd64":   "x86_64-linux-gnu-gcc",
+	"linux/arm":     "arm-linux-gnueabihf-gcc",
+	"linux/arm64":   "aarch64-linux-gnu-gcc",
+	"linux/ppc64le": "powerpc64le-linux-gnu-gcc",
+	"linux/riscv64": "riscv64-linux-gnu-gcc",
+	"linux/s390x":   "s390x-linux-gnu-gcc",
+	"windows/386":   "i686-w64-mingw32-gcc",
+	"windows/amd64": "x86_64-w64-mingw32-gcc",
+}
+
+// cgoCompilers are the C cross-compilers of the distribution's builds, none
+// unless it is built with cgo.
+func cgoCompilers(dist Distribution) map[string]string {
+	if !dist.Release.Cgo {
+		return nil
+	}
+	return CgoCompilers
+}
@@ -26,82 +26,575 @@ import (
 
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/nfpm/v2/files"
+	"golang.org/x/mod/semver"
 )
 
 const ArmArch = "arm"
 
+// ECRPublicRegistry is the registry of Amazon ECR Public, whose repositories
+// are under a registry alias, such as public.ecr.aws/<alias>.
+const ECRPublicRegistry = "public.ecr.aws"
+
+// WASIPTarget is the WebAssembly System Interface target, which requires Go
+// 1.21 or later.
+const WASIPTarget = "wasip1/wasm"
+
 var (
-	ImagePrefixes = []string{"otel", "ghcr.io/open-telemetry/opentelemetry-collector-releases"}
-	Architectures = []string{"386", "amd64", "arm", "arm64", "ppc64le", "s390x"}
-	ArmVersions   = []string{"7"}
+	ImagePrefixes    = []string{"otel", "ghcr.io/open-telemetry/opentelemetry-collector-releases"}
+	OperatingSystems = []string{"darwin", "linux", "windows"}
+	Architectures    = []string{"386", "amd64", "arm", "arm64", "loong64", "ppc64le", "riscv64", "s390x"}
+
+	// ArmVersions default to ARMv6 for the Raspberry Pi Zero and 1, on top
+	// of ARMv7.
+	ArmVersions = []string{"6", "7"}
+
+	// UnsupportedTargets are left out of every build, as <goos>/<goarch>:
+	// darwin/ppc64le, windows/ppc64le and their loong64 and riscv64
+	// counterparts aren't supported by Go, goreleaser skips them anyway.
+	UnsupportedTargets = []string{
+		"darwin/386",
+		"darwin/arm",
+		"darwin/loong64",
+		"darwin/ppc64le",
+		"darwin/riscv64",
+		"darwin/s390x",
+		"windows/arm",
+		"windows/loong64",
+		"windows/ppc64le",
+		"windows/riscv64",
+		"windows/s390x",
+	}
+
+	// Amd64Levels are the GOAMD64 microarchitecture levels distributions may
+	// build for.
+	Amd64Levels = []string{"v1", "v2", "v3", "v4"}
+
+	// OptInArmVersions are the GOARM versions distributions may opt in to
+	// through their arm versions, such as the soft-float ARMv5 for gateways
+	// without an FPU. They get no images, as the base images aren't
+	// published for them.
+	OptInArmVersions = []string{"5"}
+
+	// ImagelessArchitectures get binaries, archives and packages but no
+	// images, as the alpine image the certificates are copied from isn't
+	// published for them.
+	ImagelessArchitectures = []string{"loong64", "riscv64"}
+
+	// ExposedPorts are the ports exposed by the images: OTLP gRPC,
+	// OpenCensus and zPages.
+	ExposedPorts = []string{"4317", "55678", "55679"}
+
+	// DefaultUID is the unprivileged user the images run as, unless the
+	// distribution sets its own.
+	DefaultUID = 10001
+
+	// OptInTargets are the platforms distributions may opt in to through
+	// their extra targets, when their components support them. wasip1/wasm
+	// is experimental, for WASM runtimes.
+	OptInTargets = []string{"aix/ppc64", "freebsd/amd64", "freebsd/arm64", "illumos/amd64", "linux/mips64le", "linux/mipsle", "netbsd/amd64", "openbsd/amd64", "solaris/amd64", WASIPTarget}
+
+	// ReservedEnv are the environment variables of the builds set from the
+	// manifest's other settings or per target, which the distributions' env
+	// can't override.
+	ReservedEnv = []string{"CC", "CGO_ENABLED", "GOAMD64", "GOARCH", "GOARM", "GOMIPS", "GOOS", "GOTOOLCHAIN"}
+
+	// ReservedBuildArgs are the build arguments of the images set per image
+	// variant or from the manifest's other settings, which the
+	// distributions' build args can't override.
+	ReservedBuildArgs = []string{"BINARY", "CONFIG", "GOAMD64", "GOPROXY", "GO_VERSION", "SOURCES", "SOURCE_DATE_EPOCH", "USER_GID", "USER_UID"}
+
+	// DistDir is goreleaser's output directory, left to goreleaser's default,
+	// dist, when empty.
+	DistDir string
+)
+
+// commitTimestamp and commitDate are the modification time of the files
+// released, as a Unix timestamp and as RFC 3339, the commit's rather than the
+// build's, so that two builds of the same commit produce identical artifacts.
+const (
+	commitTimestamp = "{{ .CommitTimestamp }}"
+	commitDate      = "{{ .CommitDate }}"
 )
 
-func Generate(imagePrefixes []string, dists []string) config.Project {
+var (
+	// ProjectName is the name of the goreleaser project of this repository,
+	// which forks releasing their own distributions can change.
+	ProjectName = "opentelemetry-collector-releases"
+
+	// ChecksumNameTemplate is the name template of the checksums file.
+	// https://goreleaser.com/customization/checksum/
+	ChecksumNameTemplate = "{{ .ProjectName }}_checksums.txt"
+
+	// SkipLatest leaves the latest image tags of every distribution out,
+	// like the distributions setting skip_latest.
+	SkipLatest bool
+)
+
+// Project is the goreleaser configuration, extended with the goreleaser-pro
+// settings used by the release workflow.
+type Project struct {
+	Includes       []Include `yaml:"includes,omitempty"`
+	Partial        Partial   `yaml:"partial,omitempty"`
+	config.Project `yaml:",inline"`
+
+	// BeforePublish are goreleaser-pro's hooks run before publishing, which
+	// config.Project lacks.
+	BeforePublish []BeforePublishHook `yaml:"before_publish,omitempty"`
+
+	// Prebuilts are the settings of the prebuilt builds, by build ID, which
+	// config.Build lacks. MarshalYAML adds them to the builds.
+	Prebuilts map[string]Prebuilt `yaml:"-"`
+}
+
+// Include makes goreleaser-pro merge another configuration file into the
+// project.
+// https://goreleaser.com/customization/includes/
+type Include struct {
+	FromFile IncludeFromFile `yaml:"from_file"`
+}
+
+type IncludeFromFile struct {
+	Path string `yaml:"path"`
+}
+
+// Partial configures how goreleaser-pro splits the release across jobs.
+// https://goreleaser.com/customization/partial/
+type Partial struct {
+	By string `yaml:"by,omitempty"`
+}
+
+// Generate returns the self-contained configuration releasing the given
+// distributions.
+func Generate(imagePrefixes []string, dists []Distribution, releases []string) Project {
+	project := DistributionsProject(ProjectName, imagePrefixes, dists, releases)
+	platforms := config.ExtraFile{
+		Glob:         PlatformsFile,
+		NameTemplate: "{{ .ProjectName }}_{{ .Version }}_platforms.json",
+	}
+	inventory := config.ExtraFile{
+		Glob: path.Join(InventoryDir, "*"),
+	}
+	deltas := config.ExtraFile{
+		Glob: path.Join(DeltaDir, "*"),
+	}
+	project.Release.ExtraFiles = append(project.Release.ExtraFiles, platforms, inventory, deltas)
+	project.Checksum.ExtraFiles = append(project.Checksum.ExtraFiles, platforms, inventory, deltas)
+	common := Common()
+	project.Checksum.NameTemplate = common.Checksum.NameTemplate
+	project.Changelog = common.Changelog
+	project.Git = common.Git
+	return project
+}
+
+// Common returns the settings shared by every project released by the
+// OpenTelemetry Collector maintainers, so that related pipelines stay
+// consistent.
+func Common() config.Project {
 	return config.Project{
-		ProjectName: "opentelemetry-collector-releases",
 		Checksum: config.Checksum{
-			NameTemplate: "{{ .ProjectName }}_checksums.txt",
+			NameTemplate: ChecksumNameTemplate,
+		},
+		Changelog: Changelog(),
+		Git:       Git(),
+	}
+}
+
+// DistributionsProject returns the configuration building and publishing the
+// artifacts of the given distributions, without the common settings. The
+// release tags decide which mutable image tags the release moves.
+func DistributionsProject(name string, imagePrefixes []string, dists []Distribution, releases []string) Project {
+	return Project{
+		Partial: Partial{
+			By: SplitBy(dists),
+		},
+		Prebuilts:     Prebuilts(dists),
+		BeforePublish: ImageScans(),
+		Project: config.Project{
+			ProjectName: name,
+			Dist:        DistDir,
+			Metadata: config.ProjectMetadata{
+				ModTimestamp: commitTimestamp,
+			},
+			Before: config.Before{
+				Hooks: []string{VerifyToolchainHook(dists)},
+			},
+
+			Release:           Release(dists),
+			Checksum:          Checksum(dists),
+			Builds:            Builds(dists),
+			UniversalBinaries: UniversalBinaries(dists),
+			Archives:          Archives(dists),
+			NFPMs:             Packages(dists),
+			Dockers:           DockerImages(imagePrefixes, dists),
+			DockerManifests:   DockerManifests(imagePrefixes, dists, releases),
+			Kos:               KoImages(imagePrefixes, dists, releases),
+			Uploads:           Uploads(dists),
+			SBOMs:             SBOMs(),
+			Signs:             Signs(),
+			DockerSigns:       append(DockerSigns(), ImageSBOMs()...),
 		},
+	}
+}
 
-		Builds:          Builds(dists),
-		Archives:        Archives(dists),
-		NFPMs:           Packages(dists),
-		Dockers:         DockerImages(imagePrefixes, dists),
-		DockerManifests: DockerManifests(imagePrefixes, dists),
+// Release configures the GitHub release, attaching the extra files declared
+// by the distributions.
+// https://goreleaser.com/customization/release/
+func Release(dists []Distribution) config.Release {
+	return config.Release{
+		// Release candidates, such as v0.89.0-rc.1, are marked as prereleases.
+		Prerelease: "auto",
+		ExtraFiles: extraFiles(dists),
 	}
 }
 
-func Builds(dists []string) (r []config.Build) {
+// Checksum configures the checksums file, which covers the archives and the
+// packages by default, to also cover the extra files attached to the release.
+// https://goreleaser.com/customization/checksum/
+func Checksum(dists []Distribution) config.Checksum {
+	return config.Checksum{
+		ExtraFiles: extraFiles(dists),
+	}
+}
+
+// extraFiles are the extra files declared by the distributions, attaching
+// files declared by several distributions only once.
+func extraFiles(dists []Distribution) (r []config.ExtraFile) {
+	seen := map[string]bool{}
 	for _, dist := range dists {
-		r = append(r, Build(dist))
+		for _, file := range dist.Release.ExtraFiles {
+			if seen[file.Glob] {
+				continue
+			}
+			seen[file.Glob] = true
+			r = append(r, file)
+		}
 	}
 	return
 }
 
+// Uploads configures the HTTP uploads declared by the distributions, each
+// restricted to the artifacts of its distribution. Credentials are read by
+// goreleaser from the UPLOAD_<NAME>_USERNAME and UPLOAD_<NAME>_SECRET
+// environment variables.
+// https://goreleaser.com/customization/upload/
+func Uploads(dists []Distribution) (r []config.Upload) {
+	for _, dist := range dists {
+		for _, upload := range dist.Release.Uploads {
+			if len(upload.IDs) == 0 {
+				upload.IDs = artifactIDs(dist)
+			}
+			r = append(r, upload)
+		}
+	}
+	return
+}
+
+// Changelog configures the release notes, grouping commits by their
+// conventional commit type and leaving out the ones irrelevant to users.
+// https://goreleaser.com/customization/changelog/
+func Changelog() config.Changelog {
+	return config.Changelog{
+		Use:  "github",
+		Sort: "asc",
+		Filters: config.Filters{
+			Exclude: []string{
+				`^docs(\(.+\))?:`,
+				`^test(\(.+\))?:`,
+				`^ci(\(.+\))?:`,
+				`^Merge (pull request|branch)`,
+			},
+		},
+		Groups: []config.ChangelogGroup{
+			{Title: "Breaking changes", Regexp: `^.*?[[:word:]]+(\(.+\))?!:.+$`, Order: 0},
+			{Title: "New features", Regexp: `^.*?feat(\(.+\))?:.+$`, Order: 1},
+			{Title: "Bug fixes", Regexp: `^.*?fix(\(.+\))?:.+$`, Order: 2},
+			{Title: "Dependency updates", Regexp: `^.*?(deps(\(.+\))?:|[Bb]ump ).+$`, Order: 3},
+			{Title: "Other changes", Order: 999},
+		},
+	}
+}
+
+// Git configures how tags are sorted to find the previous release, used
+// for the changelog and version templates. Tags are sorted by version rather
+// than by date, so that patch releases tagged after a newer minor release
+// still resolve the right previous tag, and any suffix starting with a dash
+// marks a prerelease sorting before its release, e.g. v0.89.0-rc.1 < v0.89.0.
+// https://goreleaser.com/customization/git/
+func Git() config.Git {
+	return config.Git{
+		TagSort:          "-version:refname",
+		PrereleaseSuffix: "-",
+	}
+}
+
+func Builds(dists []Distribution) (r []config.Build) {
+	for _, dist := range dists {
+		builds := []config.Build{Build(dist)}
+		if dist.Release.OpAMPSupervisor {
+			builds = append(builds, SupervisorBuild(dist))
+		}
+		if dist.Release.FIPS {
+			builds = append(builds, FIPSBuild(dist))
+		}
+		if dist.Release.Musl {
+			builds = append(builds, MuslBuild(dist))
+		}
+		if dist.Release.DebugSymbols {
+			builds = append(builds, DebugBuild(dist))
+		}
+		for _, variant := range dist.Release.BuildVariants {
+			builds = append(builds, VariantBuild(dist, variant))
+		}
+		if dist.Release.Prebuilt != "" {
+			for i := range builds {
+				builds[i] = PrebuiltBuild(builds[i])
+			}
+		}
+		r = append(r, builds...)
+	}
+	return
+}
+
+// UniversalBinaries merge the darwin binaries of the distributions opting in
+// to a universal binary. It has the ID of the build, so that it is archived
+// with the other binaries, as the "all" architecture.
+// https://goreleaser.com/customization/universalbinaries/
+func UniversalBinaries(dists []Distribution) (r []config.UniversalBinary) {
+	for _, dist := range dists {
+		if !dist.Release.UniversalBinary {
+			continue
+		}
+		r = append(r, config.UniversalBinary{
+			ID:           dist.ID,
+			IDs:          []string{dist.ID},
+			NameTemplate: dist.BinaryName(),
+			ModTimestamp: commitTimestamp,
+		})
+	}
+	return
+}
+
+// SplitBy is how the release is split across jobs: by target, unless a
+// distribution has a universal binary, whose darwin binaries must be built by
+// the same job, in which case it is split by GOOS.
+func SplitBy(dists []Distribution) string {
+	for _, dist := range dists {
+		if dist.Release.UniversalBinary {
+			return "goos"
+		}
+	}
+	return "target"
+}
+
 // Build configures a goreleaser build.
 // https://goreleaser.com/customization/build/
-func Build(dist string) config.Build {
-	return config.Build{
-		ID:     dist,
-		Dir:    path.Join("distributions", dist, "_build"),
-		Binary: dist,
+func Build(dist Distribution) config.Build {
+	// The VCS information depends on the untracked files of the checkout, the
+	// commit is stamped by the ldflags instead.
+	flags := []string{"-trimpath", "-buildvcs=false"}
+	if profile := dist.PGOProfile(); profile != "" {
+		flags = append(flags, "-pgo="+profile)
+	}
+	if Offline {
+		flags = append(flags, "-mod=vendor")
+	}
+
+	goos, goarch, ignore := buildMatrix(dist)
+	dir := path.Join("distributions", dist.ID, "_build")
+	b := config.Build{
+		ID:     dist.ID,
+		Dir:    dir,
+		Binary: dist.BinaryName(),
 		BuildDetails: config.BuildDetails{
-			Env:     []string{"CGO_ENABLED=0"},
-			Flags:   []string{"-trimpath"},
-			Ldflags: []string{"-s", "-w"},
-		},
-		Goos:   []string{"darwin", "linux", "windows"},
-		Goarch: Architectures,
-		Goarm:  ArmVersions,
-		Ignore: []config.IgnoredBuild{
-			{Goos: "darwin", Goarch: "386"},
-			{Goos: "darwin", Goarch: "arm"},
-			{Goos: "darwin", Goarch: "s390x"},
-			{Goos: "windows", Goarch: "arm"},
-			{Goos: "windows", Goarch: "arm64"},
-			{Goos: "windows", Goarch: "s390x"},
+			Env:     buildEnv(dist, dist.Release.Cgo),
+			Flags:   flags,
+			Ldflags: append([]string{"-s", "-w"}, VersionLdflags...),
+			Tags:    dist.BuildTags(),
 		},
+		Goos:    goos,
+		Goarch:  goarch,
+		Goarm:   dist.ArmVersions(),
+		Goamd64: dist.Release.Goamd64,
+		Gomips:  dist.Release.Gomips,
+		Ignore:  ignore,
+
+		Hooks:        buildHooks(dist, dir),
+		ModTimestamp: commitTimestamp,
+	}
+	b.BuildDetailsOverrides = targetOverrides(dist, b, cgoCompilers(dist))
+	return b
+}
+
+// buildHooks are the distribution's build hooks. Unless they set their own
+// directory, the pre hooks run in dir, the build's, and the post hooks in the
+// repository, which the binary's {{ .Path }} is relative to.
+func buildHooks(dist Distribution, dir string) config.BuildHookConfig {
+	var pre config.Hooks
+	for _, h := range dist.Release.Hooks.Pre {
+		if h.Dir == "" {
+			h.Dir = dir
+		}
+		pre = append(pre, h)
+	}
+	return config.BuildHookConfig{
+		Pre:  pre,
+		Post: dist.Release.Hooks.Post,
+	}
+}
+
+// buildEnv is the environment of a distribution's builds, with or without
+// cgo, followed by the distribution's own variables.
+func buildEnv(dist Distribution, cgo bool) []string {
+	env := []string{"CGO_ENABLED=0"}
+	if cgo {
+		env = []string{"CGO_ENABLED=1"}
+	}
+	if goVersion := dist.GoVersion(); goVersion != "" {
+		// Go 1.21+ switches to, and downloads if needed, the given toolchain.
+		env = append(env, fmt.Sprintf("GOTOOLCHAIN=go%s", goVersion))
+	}
+	env = append(env, dist.Release.Env...)
+	if Offline {
+		// Last, so that the distribution's env can't turn the proxy back on.
+		env = append(env, "GOPROXY=off")
+	}
+	return env
+}
+
+// buildMatrix returns the GOOS and GOARCH values to build a distribution for,
+// and the combinations of them to leave out. These are the default platforms
+// plus the extra targets the distribution opts in to.
+func buildMatrix(dist Distribution) (goos, goarch []string, ignore []config.IgnoredBuild) {
+	goos = append([]string{}, dist.Goos()...)
+	goarch = append([]string{}, dist.Goarch()...)
+	for _, target := range dist.Release.ExtraTargets {
+		o, arch, _ := strings.Cut(target, "/")
+		if !contains(goos, o) {
+			goos = append(goos, o)
+		}
+		if !contains(goarch, arch) {
+			goarch = append(goarch, arch)
+		}
 	}
+	return goos, goarch, Ignore(dist, goos, goarch)
+}
+
+// Ignore returns the goreleaser ignore entries leaving out of the given GOOS
+// and GOARCH matrix the unsupported targets, the ones the distribution
+// excludes, and the ones outside of its default platforms, unless it opts in
+// to them as extra targets. Excluded arm versions are left out on their own.
+// cgo distributions also leave out the targets without a C cross-compiler.
+// https://goreleaser.com/customization/build/
+func Ignore(dist Distribution, goos, goarch []string) (r []config.IgnoredBuild) {
+	excluded := append(append([]string{}, UnsupportedTargets...), dist.Release.ExcludeTargets...)
+	for _, o := range goos {
+		for _, arch := range goarch {
+			target := fmt.Sprintf("%s/%s", o, arch)
+			switch {
+			case dist.Release.Cgo && CgoCompilers[target] == "":
+				r = append(r, config.IgnoredBuild{Goos: o, Goarch: arch})
+			case contains(dist.Release.ExtraTargets, target):
+			case !contains(dist.Goos(), o) || !contains(dist.Goarch(), arch) || contains(excluded, target):
+				r = append(r, config.IgnoredBuild{Goos: o, Goarch: arch})
+			case arch == ArmArch:
+				for _, armVersion := range dist.ArmVersions() {
+					if contains(excluded, fmt.Sprintf("%s/v%s", target, armVersion)) {
+						r = append(r, config.IgnoredBuild{Goos: o, Goarch: arch, Goarm: armVersion})
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// VariantBuild configures the goreleaser build of a distribution's build
+// variant, compiled from its own generated sources.
+func VariantBuild(dist Distribution, variant BuildVariant) config.Build {
+	b := Build(dist)
+	b.ID = dist.VariantID(variant)
+	b.Dir = path.Join("distributions", dist.ID, VariantBuildDir(variant))
+	b.Binary = dist.VariantBinaryName(variant)
+	b.Tags = append(b.Tags, variant.Tags...)
+	b.Hooks = buildHooks(dist, b.Dir)
+	b.BuildDetailsOverrides = targetOverrides(dist, b, cgoCompilers(dist))
+	return b
 }
 
-func Archives(dists []string) (r []config.Archive) {
+func Archives(dists []Distribution) (r []config.Archive) {
 	for _, dist := range dists {
-		r = append(r, Archive(dist))
+		r = append(r, Archive(dist), LatestArchive(dist))
+		if dist.Release.FIPS {
+			r = append(r, FIPSArchive(dist))
+		}
+		if dist.Release.Musl {
+			r = append(r, MuslArchive(dist))
+		}
+		if dist.Release.DebugSymbols {
+			r = append(r, DebugArchive(dist))
+		}
+		for _, variant := range dist.Release.BuildVariants {
+			a := Archive(dist)
+			a.ID = dist.VariantID(variant)
+			a.NameTemplate = archiveNameTemplate
+			a.Builds = []string{dist.VariantID(variant)}
+			r = append(r, a)
+		}
 	}
 	return
 }
 
+const archiveNameTemplate = `{{ .Binary }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}{{ if .Arm }}v{{ .Arm }}{{ end }}{{ if and .Amd64 (ne .Amd64 "v1") }}{{ .Amd64 }}{{ end }}{{ if .Mips }}_{{ .Mips }}{{ end }}`
+
 // Archive configures a goreleaser archive (tarball).
 // https://goreleaser.com/customization/archive/
-func Archive(dist string) config.Archive {
-	return config.Archive{
-		ID:           dist,
-		NameTemplate: "{{ .Binary }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}{{ if .Arm }}v{{ .Arm }}{{ end }}{{ if .Mips }}_{{ .Mips }}{{ end }}",
-		Builds:       []string{dist},
+func Archive(dist Distribution) config.Archive {
+	a := config.Archive{
+		ID:           dist.ID,
+		NameTemplate: archiveNameTemplate,
+		Builds:       []string{dist.ID},
+		BuildsInfo:   config.FileInfo{MTime: commitDate},
+		Files:        archiveFiles(),
+	}
+	if dist.Release.OpAMPSupervisor {
+		// With several binaries, .Binary may be either of them.
+		a.NameTemplate = strings.Replace(archiveNameTemplate, "{{ .Binary }}", dist.BinaryName(), 1)
+		a.Builds = append(a.Builds, dist.SupervisorID())
+	}
+	return a
+}
+
+// archiveFiles are the files archived along with the binaries, goreleaser's
+// default ones found in the repository, with the commit's modification time.
+func archiveFiles() []config.File {
+	return []config.File{
+		{Source: "LICENSE", Info: config.FileInfo{MTime: commitDate}},
+		{Source: "README.md", Info: config.FileInfo{MTime: commitDate}},
 	}
 }
 
-func Packages(dists []string) (r []config.NFPM) {
+// LatestArchive configures a copy of the distribution's archives named
+// without the version, such as otelcol_latest_linux_amd64.tar.gz, so that
+// scripts can download the newest release from
+// https://github.com/<repo>/releases/latest/download/<archive> without
+// querying the GitHub API. GitHub never resolves "latest" to a prerelease.
+func LatestArchive(dist Distribution) config.Archive {
+	a := Archive(dist)
+	a.ID = dist.ID + "-latest"
+	a.NameTemplate = strings.Replace(a.NameTemplate, "{{ .Version }}", "latest", 1)
+	return a
+}
+
+func Packages(dists []Distribution) (r []config.NFPM) {
 	for _, dist := range dists {
 		r = append(r, Package(dist))
 	}
@@ -110,53 +603,273 @@ func Packages(dists []string) (r []config.NFPM) {
 
 // Package configures goreleaser to build a system package.
 // https://goreleaser.com/customization/nfpm/
-func Package(dist string) config.NFPM {
+func Package(dist Distribution) config.NFPM {
+	builds := []string{dist.ID}
+	if dist.Release.OpAMPSupervisor {
+		builds = append(builds, dist.SupervisorID())
+	}
 	return config.NFPM{
-		ID:      dist,
-		Builds:  []string{dist},
+		ID:      dist.ID,
+		Builds:  builds,
 		Formats: []string{"apk", "deb", "rpm"},
 
-		License:     "Apache 2.0",
-		Description: fmt.Sprintf("OpenTelemetry Collector - %s", dist),
-		Maintainer:  "The OpenTelemetry Collector maintainers <cncf-opentelemetry-maintainers@lists.cncf.io>",
+		License:     dist.License(),
+		Description: dist.Description(),
+		Homepage:    dist.Homepage(),
+		Maintainer:  dist.Maintainer(),
+		Vendor:      dist.Vendor(),
 
 		NFPMOverridables: config.NFPMOverridables{
-			PackageName: dist,
+			PackageName: dist.ID,
 			Scripts: config.NFPMScripts{
-				PreInstall:  path.Join("distributions", dist, "preinstall.sh"),
-				PostInstall: path.Join("distributions", dist, "postinstall.sh"),
-				PreRemove:   path.Join("distributions", dist, "preremove.sh"),
-			},
-			Contents: files.Contents{
-				{
-					Source:      path.Join("distributions", dist, fmt.Sprintf("%s.service", dist)),
-					Destination: path.Join("/lib", "systemd", "system", fmt.Sprintf("%s.service", dist)),
-				},
-				{
-					Source:      path.Join("distributions", dist, fmt.Sprintf("%s.conf", dist)),
-					Destination: path.Join("/etc", dist, fmt.Sprintf("%s.conf", dist)),
-					Type:        "config|noreplace",
-				},
-				{
-					Source:      path.Join("configs", fmt.Sprintf("%s.yaml", dist)),
-					Destination: path.Join("/etc", dist, "config.yaml"),
-					Type:        "config",
-				},
+				PreInstall:  path.Join("distributions", dist.ID, "preinstall.sh"),
+				PostInstall: path.Join("distributions", dist.ID, "postinstall.sh"),
+				PreRemove:   path.Join("distributions", dist.ID, "preremove.sh"),
 			},
+			Contents: packageContents(dist),
 		},
 	}
 }
 
-func DockerImages(imagePrefixes, dists []string) (r []config.Docker) {
+func packageContents(dist Distribution) files.Contents {
+	service := dist.ServiceName()
+	contents := files.Contents{
+		{
+			Source:      path.Join("distributions", dist.ID, fmt.Sprintf("%s.service", service)),
+			Destination: path.Join("/lib", "systemd", "system", fmt.Sprintf("%s.service", service)),
+		},
+		{
+			Source:      path.Join("distributions", dist.ID, fmt.Sprintf("%s.conf", service)),
+			Destination: path.Join("/etc", service, fmt.Sprintf("%s.conf", service)),
+			Type:        "config|noreplace",
+		},
+	}
+
+	if dist.Release.OpAMPSupervisor {
+		contents = append(contents,
+			&files.Content{
+				Source:      path.Join("distributions", dist.ID, fmt.Sprintf("%s-supervisor.service", service)),
+				Destination: path.Join("/lib", "systemd", "system", fmt.Sprintf("%s-supervisor.service", service)),
+			},
+			&files.Content{
+				Source:      path.Join("distributions", dist.ID, "supervisor.yaml"),
+				Destination: path.Join("/etc", service, "supervisor.yaml"),
+				Type:        "config|noreplace",
+			},
+		)
+	}
+
+	// With config variants, config.yaml is an alternative pointing to either
+	// the default config or one of the variants, managed by the package scripts.
+	if len(dist.Release.ConfigVariants) == 0 {
+		return append(contents, &files.Content{
+			Source:      path.Join("configs", fmt.Sprintf("%s.yaml", dist.ID)),
+			Destination: path.Join("/etc", service, "config.yaml"),
+			Type:        "config",
+		})
+	}
+	contents = append(contents, &files.Content{
+		Source:      path.Join("configs", fmt.Sprintf("%s.yaml", dist.ID)),
+		Destination: path.Join("/etc", service, "default.yaml"),
+		Type:        "config",
+	})
+	for _, variant := range dist.Release.ConfigVariants {
+		contents = append(contents, &files.Content{
+			Source:      configVariantPath(dist, variant),
+			Destination: path.Join("/etc", service, fmt.Sprintf("%s.yaml", variant)),
+			Type:        "config",
+		})
+	}
+	return contents
+}
+
+// FloatingTags are the mutable tags following a release line, such as 0.89
+// and 0, which users pin to get the patch releases automatically.
+var FloatingTags = []string{"{{ .Major }}.{{ .Minor }}", "{{ .Major }}"}
+
+// MovedTags are the mutable tags a release of the distribution, at its
+// version, moves: latest unless skipped, and the FloatingTags of its release
+// lines, left out of the prereleases. Each is only moved when the release is
+// the latest of its line among the release tags, so that a patch of an older
+// line, such as 0.88.2 after 0.89.0, doesn't move latest nor the major tag
+// back.
+func MovedTags(dist Distribution, releases []string) (r []string) {
+	v := "v" + strings.TrimPrefix(dist.Dist.Version, "v")
+	if !dist.SkipsLatest() && LatestInLine(v, "", releases) {
+		r = append(r, "latest")
+	}
+	if semver.IsValid(v) && semver.Prerelease(v) == "" {
+		for i, line := range []string{semver.MajorMinor(v), semver.Major(v)} {
+			if LatestInLine(v, line, releases) {
+				r = append(r, FloatingTags[i])
+			}
+		}
+	}
+	return
+}
+
+// ImageVariant is a flavor of a distribution's container image, published
+// under its own tags.
+type ImageVariant struct {
+	// Suffix is appended to the version in the image tags, e.g. "agent"
+	// results in "0.89.0-agent". The default image has no suffix.
+	Suffix string
+	// BuildID is the ID of the build providing the binary.
+	BuildID string
+	// ExtraBuildIDs are the builds providing additional binaries.
+	ExtraBuildIDs []string
+	BuildArgs     []string
+	// Files are copied into the image. The first one is the default config.
+	Files []string
+	// Dockerfile overrides the distribution's Dockerfile.
+	Dockerfile string
+	// Archs restrict the images to some of the distribution's architectures.
+	Archs []string
+	// BaseImage overrides the distribution's base image, for the variants
+	// with their own Dockerfile.
+	BaseImage string
+}
+
+// platforms are the platforms the images of the variant are built for.
+func (v ImageVariant) platforms(dist Distribution) (r []imagePlatform) {
+	for _, p := range imagePlatforms(dist) {
+		if len(v.Archs) == 0 || contains(v.Archs, p.arch) {
+			r = append(r, p)
+		}
+	}
+	return
+}
+
+// tag returns the image tag for the given version in this variant.
+func (v ImageVariant) tag(version string) string {
+	if v.Suffix == "" {
+		return version
+	}
+	return fmt.Sprintf("%s-%s", version, v.Suffix)
+}
+
+// ImageVariants lists the images to build for a distribution: the default
+// one, plus one per config variant, build variant and asset variant, and the
+// FIPS, distroless, UBI and supervisor images of the distributions opting in.
+// Every image holds the distribution's bundled configs.
+func ImageVariants(dist Distribution) []ImageVariant {
+	defaultConfig := path.Join("configs", fmt.Sprintf("%s.yaml", dist.ID))
+	variants := []ImageVariant{{
+		BuildID: dist.ID,
+		Files:   []string{defaultConfig},
+	}}
+	for _, variant := range dist.Release.ConfigVariants {
+		cfg := configVariantPath(dist, variant)
+		variants = append(variants, ImageVariant{
+			Suffix:    variant,
+			BuildID:   dist.ID,
+			BuildArgs: []string{fmt.Sprintf("--build-arg=CONFIG=%s", cfg)},
+			Files:     []string{cfg},
+		})
+	}
+	for _, variant := range dist.Release.BuildVariants {
+		variants = append(variants, ImageVariant{
+			Suffix:    variant.Name,
+			BuildID:   dist.VariantID(variant),
+			BuildArgs: []string{fmt.Sprintf("--build-arg=BINARY=%s", dist.VariantBinaryName(variant))},
+			Files:     []string{defaultConfig},
+		})
+	}
+	for _, variant := range dist.Release.Docker.AssetVariants {
+		files := []string{defaultConfig}
+		for _, asset := range variant.Assets {
+			files = append(files, assetPath(dist, variant, asset))
+		}
+		variants = append(variants, ImageVariant{
+			Suffix:     variant.Name,
+			BuildID:    dist.ID,
+			Files:      files,
+			Dockerfile: path.Join(AssetVariantDir(dist, variant), "Dockerfile"),
+		})
+	}
+	if dist.Release.FIPS {
+		variants = append(variants, ImageVariant{
+			Suffix:    "fips",
+			BuildID:   dist.FIPSID(),
+			BuildArgs: []string{fmt.Sprintf("--build-arg=BINARY=%s", dist.FIPSBinaryName())},
+			Files:     []string{defaultConfig},
+			Archs:     fipsArchitectures(dist),
+		})
+	}
+	if dist.PublishesDistroless() {
+		variants = append(variants, distrolessImageVariant(dist))
+	}
+	if dist.Release.Docker.UBI {
+		variants = append(variants, ubiImageVariant(dist))
+	}
+	if dist.Release.OpAMPSupervisor {
+		variants = append(variants, ImageVariant{
+			Suffix:        "supervisor",
+			BuildID:       dist.ID,
+			ExtraBuildIDs: []string{dist.SupervisorID()},
+			Files:         []string{defaultConfig, supervisorImageConfig(dist)},
+			Dockerfile:    supervisorImageDockerfile(dist),
+		})
+	}
+	for i := range variants {
+		variants[i].Files = withBundledConfigs(dist, variants[i].Files)
+	}
+	if dist.Release.Docker.Hermetic {
+		for i, variant := range variants {
+			sources := path.Join("distributions", dist.ID, "_build")
+			for _, bv := range dist.Release.BuildVariants {
+				if dist.VariantID(bv) == variant.BuildID {
+					sources = path.Join("distributions", dist.ID, VariantBuildDir(bv))
+					variants[i].BuildArgs = append(variants[i].BuildArgs, fmt.Sprintf("--build-arg=SOURCES=%s", sources))
+				}
+			}
+			// The binary is compiled from the sources copied into the build
+			// context, with the profile and the ldflags of the host builds.
+			variants[i].Files = append(variants[i].Files, sources)
+			if profile := pgoProfileFile(dist); profile != "" {
+				variants[i].Files = append(variants[i].Files, profile)
+			}
+			variants[i].BuildArgs = append(variants[i].BuildArgs, "--build-arg=LDFLAGS="+strings.Join(append([]string{"-s", "-w"}, VersionLdflags...), " "))
+			if Offline {
+				variants[i].BuildArgs = append(variants[i].BuildArgs, "--build-arg=GOPROXY=off")
+			}
+			if variant.Dockerfile == "" {
+				variants[i].Dockerfile = dist.ImageDockerfile()
+			}
+		}
+	}
+	return variants
+}
+
+// DockerImages configures the images of the distributions built by docker
+// buildx, all but the ones built by apko or ko.
+func DockerImages(imagePrefixes []string, dists []Distribution) (r []config.Docker) {
 	for _, dist := range dists {
-		for _, arch := range Architectures {
-			switch arch {
-			case ArmArch:
-				for _, vers := range ArmVersions {
-					r = append(r, DockerImage(imagePrefixes, dist, arch, vers))
+		if dist.Release.Docker.Apko || dist.Release.Docker.Ko {
+			continue
+		}
+		for _, variant := range ImageVariants(dist) {
+			for _, p := range variant.platforms(dist) {
+				r = append(r, DockerImage(imagePrefixes, dist, variant, p.arch, p.armVersion))
+				if p.arch != "amd64" {
+					continue
+				}
+				// The other GOAMD64 levels are tagged with the level, such as
+				// <version>-amd64v3, and left out of the manifests.
+				for _, level := range dist.Release.Goamd64 {
+					if level == "v1" {
+						continue
+					}
+					image := DockerImage(imagePrefixes, dist, variant, p.arch, p.armVersion)
+					image.Goamd64 = level
+					if dist.Release.Docker.Hermetic {
+						image.BuildFlagTemplates = append(image.BuildFlagTemplates, "--build-arg=GOAMD64="+level)
+					}
+					for i, template := range image.ImageTemplates {
+						image.ImageTemplates[i] = template + level
+					}
+					r = append(r, image)
 				}
-			default:
-				r = append(r, DockerImage(imagePrefixes, dist, arch, ""))
 			}
 		}
 	}
@@ -165,84 +878,240 @@ func DockerImages(imagePrefixes, dists []string) (r []config.Docker) {
 
 // DockerImage configures goreleaser to build a container image.
 // https://goreleaser.com/customization/docker/
-func DockerImage(imagePrefixes []string, dist, arch, armVersion string) config.Docker {
+func DockerImage(imagePrefixes []string, dist Distribution, variant ImageVariant, arch, armVersion string) config.Docker {
 	dockerArchName := archName(arch, armVersion)
 	var imageTemplates []string
 	for _, prefix := range imagePrefixes {
 		dockerArchTag := strings.ReplaceAll(dockerArchName, "/", "")
 		imageTemplates = append(
 			imageTemplates,
-			fmt.Sprintf("%s/%s:{{ .Version }}-%s", prefix, imageName(dist), dockerArchTag),
-			fmt.Sprintf("%s/%s:latest-%s", prefix, imageName(dist), dockerArchTag),
+			fmt.Sprintf("%s/%s:%s-%s", prefix, imageName(dist), variant.tag("{{ .Version }}"), dockerArchTag),
 		)
 	}
 
 	label := func(name, template string) string {
 		return fmt.Sprintf("--label=org.opencontainers.image.%s={{%s}}", name, template)
 	}
+	staticLabel := func(name, value string) string {
+		return fmt.Sprintf("--label=org.opencontainers.image.%s=%s", name, value)
+	}
+
+	var buildFlags []string
+	if !Offline {
+		// The offline builds use the base images already available.
+		buildFlags = append(buildFlags, "--pull")
+	}
+	buildFlags = append(buildFlags,
+		fmt.Sprintf("--platform=linux/%s", dockerArchName),
+		label("created", ".CommitDate"),
+		label("name", ".ProjectName"),
+		label("revision", ".FullCommit"),
+		label("version", ".Version"),
+		label("source", ".GitURL"),
+		staticLabel("title", dist.DisplayName()),
+		staticLabel("description", dist.Description()),
+		staticLabel("url", dist.Homepage()),
+		staticLabel("licenses", dist.License()),
+		staticLabel("vendor", dist.Vendor()),
+		staticLabel("documentation", dist.Documentation()),
+	)
+	baseImage := dist.BaseImage()
+	if variant.BaseImage != "" {
+		baseImage = variant.BaseImage
+	}
+	// Images built from scratch have no base image.
+	if baseImage != "" && baseImage != "scratch" {
+		buildFlags = append(buildFlags, staticLabel("base.name", baseImage))
+	}
+	// BuildKit sets the timestamps of the image to SOURCE_DATE_EPOCH.
+	buildFlags = append(buildFlags, fmt.Sprintf("--build-arg=SOURCE_DATE_EPOCH=%s", commitTimestamp))
+	if goVersion := dist.GoVersion(); goVersion != "" {
+		buildFlags = append(buildFlags, fmt.Sprintf("--build-arg=GO_VERSION=%s", goVersion))
+	}
+	buildFlags = append(buildFlags, userBuildArgs(dist)...)
+	for _, arg := range dist.Release.Docker.BuildArgs {
+		buildFlags = append(buildFlags, "--build-arg="+arg)
+	}
+	buildFlags = append(buildFlags, variant.BuildArgs...)
+
+	dockerfile := path.Join("distributions", dist.ID, "Dockerfile")
+	if variant.Dockerfile != "" {
+		dockerfile = variant.Dockerfile
+	}
 
 	return config.Docker{
+		IDs:            append([]string{variant.BuildID}, variant.ExtraBuildIDs...),
 		ImageTemplates: imageTemplates,
-		Dockerfile:     path.Join("distributions", dist, "Dockerfile"),
-
-		Use: "buildx",
-		BuildFlagTemplates: []string{
-			"--pull",
-			fmt.Sprintf("--platform=linux/%s", dockerArchName),
-			label("created", ".Date"),
-			label("name", ".ProjectName"),
-			label("revision", ".FullCommit"),
-			label("version", ".Version"),
-			label("source", ".GitURL"),
-		},
-		Files:  []string{path.Join("configs", fmt.Sprintf("%s.yaml", dist))},
-		Goos:   "linux",
-		Goarch: arch,
-		Goarm:  armVersion,
+		Dockerfile:     dockerfile,
+
+		Use:                "buildx",
+		BuildFlagTemplates: buildFlags,
+		Files:              variant.Files,
+		Goos:               "linux",
+		Goarch:             arch,
+		Goarm:              armVersion,
 	}
 }
 
-func DockerManifests(imagePrefixes, dists []string) (r []config.DockerManifest) {
+// DockerManifests configures the multi-arch manifests of every image. The
+// per-arch images are only tagged with their version, and goreleaser pushes
+// the manifests in order once every image is pushed, so the mutable tags
+// moved by the release, among the release tags, are only moved at the very
+// end of a successful release.
+func DockerManifests(imagePrefixes []string, dists []Distribution, releases []string) (r []config.DockerManifest) {
+	var latest []config.DockerManifest
 	for _, dist := range dists {
-		for _, prefix := range imagePrefixes {
-			r = append(r, DockerManifest(prefix, `{{ .Version }}`, dist))
-			r = append(r, DockerManifest(prefix, "latest", dist))
+		// Distributions not built for linux have no images, and apko and
+		// ko publish their multi-arch images themselves.
+		if len(imagePlatforms(dist)) == 0 || dist.Release.Docker.Apko || dist.Release.Docker.Ko {
+			continue
+		}
+		for _, variant := range ImageVariants(dist) {
+			for _, prefix := range imagePrefixes {
+				version := variant.tag(`{{ .Version }}`)
+				r = append(r, DockerManifest(prefix, version, version, dist, variant))
+				for _, tag := range MovedTags(dist, releases) {
+					manifest := DockerManifest(prefix, variant.tag(tag), version, dist, variant)
+					if tag != "latest" {
+						// Prereleases don't move the release lines.
+						manifest.SkipPush = "auto"
+					}
+					latest = append(latest, manifest)
+				}
+			}
 		}
 	}
-	return
+	return append(r, latest...)
 }
 
-// DockerManifest configures goreleaser to build a multi-arch container image manifest.
+// DockerManifest configures goreleaser to build a multi-arch container image
+// manifest tagged tag, listing the per-arch images of the given version.
 // https://goreleaser.com/customization/docker_manifest/
-func DockerManifest(prefix, version, dist string) config.DockerManifest {
+func DockerManifest(prefix, tag, version string, dist Distribution, variant ImageVariant) config.DockerManifest {
 	var imageTemplates []string
-	for _, arch := range Architectures {
-		switch arch {
-		case ArmArch:
-			for _, armVers := range ArmVersions {
-				dockerArchTag := strings.ReplaceAll(archName(arch, armVers), "/", "")
-				imageTemplates = append(
-					imageTemplates,
-					fmt.Sprintf("%s/%s:%s-%s", prefix, imageName(dist), version, dockerArchTag),
-				)
-			}
-		default:
-			imageTemplates = append(
-				imageTemplates,
-				fmt.Sprintf("%s/%s:%s-%s", prefix, imageName(dist), version, arch),
-			)
-		}
+	for _, p := range variant.platforms(dist) {
+		dockerArchTag := strings.ReplaceAll(archName(p.arch, p.armVersion), "/", "")
+		imageTemplates = append(
+			imageTemplates,
+			fmt.Sprintf("%s/%s:%s-%s", prefix, imageName(dist), version, dockerArchTag),
+		)
+	}
+	if variant.Suffix == "" {
+		imageTemplates = append(imageTemplates, windowsImageTemplates(prefix, version, dist)...)
 	}
 
 	return config.DockerManifest{
-		NameTemplate:   fmt.Sprintf("%s/%s:%s", prefix, imageName(dist), version),
+		NameTemplate:   fmt.Sprintf("%s/%s:%s", prefix, imageName(dist), tag),
 		ImageTemplates: imageTemplates,
 	}
 }
 
-// imageName translates a distribution name to a container image name.
-func imageName(dist string) string {
-	return strings.Replace(dist, "otelcol", "opentelemetry-collector", 1)
+// artifactIDs are the IDs of the builds, archives and packages of the
+// distribution and its build variants.
+func artifactIDs(dist Distribution) []string {
+	ids := []string{dist.ID}
+	if dist.Release.FIPS {
+		ids = append(ids, dist.FIPSID())
+	}
+	if dist.Release.Musl {
+		ids = append(ids, dist.MuslID())
+	}
+	if dist.Release.DebugSymbols {
+		ids = append(ids, dist.DebugID())
+	}
+	for _, variant := range dist.Release.BuildVariants {
+		ids = append(ids, dist.VariantID(variant))
+	}
+	return ids
+}
+
+// configVariantPath is the path to the default configuration of the given
+// variant, such as configs/otelcol-agent.yaml.
+func configVariantPath(dist Distribution, variant string) string {
+	return path.Join("configs", fmt.Sprintf("%s-%s.yaml", dist.ID, variant))
+}
+
+// bundledConfig is a config bundled into the distribution's images.
+type bundledConfig struct {
+	// Source is the path of the config, relative to the repository.
+	Source string
+	// Name is the name of the config in the image, without the extension.
+	Name string
+}
+
+// bundledConfigs are the configs bundled into the distribution's images next
+// to the default one.
+func bundledConfigs(dist Distribution) (r []bundledConfig) {
+	for _, name := range dist.Release.Docker.BundledConfigs {
+		r = append(r, bundledConfig{Source: configVariantPath(dist, name), Name: name})
+	}
+	return
+}
+
+// withBundledConfigs adds the configs bundled into the distribution's images
+// to the files of an image.
+func withBundledConfigs(dist Distribution, files []string) []string {
+	for _, cfg := range bundledConfigs(dist) {
+		if !contains(files, cfg.Source) {
+			files = append(files, cfg.Source)
+		}
+	}
+	return files
+}
+
+// imageName translates a distribution's binary name to a container image name.
+func imageName(dist Distribution) string {
+	return strings.Replace(dist.BinaryName(), "otelcol", "opentelemetry-collector", 1)
+}
+
+// imagePlatform is a platform container images are built for.
+type imagePlatform struct {
+	arch, armVersion string
+}
+
+// String returns the docker platform name, such as "linux/arm/v7".
+func (p imagePlatform) String() string {
+	return "linux/" + archName(p.arch, p.armVersion)
+}
+
+// supportedImagePlatforms are the platforms images can be built for: every
+// linux architecture of the distribution but the imageless ones, for each
+// arm version of the distribution but the opt-in ones, and neither excluded
+// by the distribution.
+func supportedImagePlatforms(dist Distribution) (r []imagePlatform) {
+	if !contains(dist.Goos(), "linux") {
+		return nil
+	}
+	for _, arch := range dist.Goarch() {
+		switch {
+		case contains(ImagelessArchitectures, arch) || contains(dist.Release.ExcludeTargets, "linux/"+arch):
+			continue
+		case dist.Release.Cgo && CgoCompilers["linux/"+arch] == "":
+			continue
+		case arch == ArmArch:
+			for _, armVersion := range dist.ArmVersions() {
+				p := imagePlatform{arch: arch, armVersion: armVersion}
+				if contains(OptInArmVersions, armVersion) || contains(dist.Release.ExcludeTargets, p.String()) {
+					continue
+				}
+				r = append(r, p)
+			}
+		default:
+			r = append(r, imagePlatform{arch: arch})
+		}
+	}
+	return
+}
+
+// imagePlatforms are the platforms the distribution's images are built for,
+// restricted to its image platforms when it declares some.
+func imagePlatforms(dist Distribution) (r []imagePlatform) {
+	for _, p := range supportedImagePlatforms(dist) {
+		if len(dist.Release.Docker.Platforms) == 0 || contains(dist.Release.Docker.Platforms, p.String()) {
+			r = append(r, p)
+		}
+	}
+	return
 }
 
 // archName translates architecture to docker platform names.
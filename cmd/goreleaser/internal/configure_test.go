@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestMovedTags releases patches of the 0.88 and 0.89 lines after 0.89.0,
+// and checks the mutable tags the buildx manifests, ko and apko images move.
+func TestMovedTags(t *testing.T) {
+	releases := []string{"v0.88.0", "v0.88.1", "v0.89.0", "v0.90.0-rc.1", "v0.90.0-nightly.20231015"}
+	const prefix = "ghcr.io/open-telemetry/opentelemetry-collector-releases"
+	const image = prefix + "/opentelemetry-collector"
+	for _, tc := range []struct {
+		version  string
+		manifest []string
+		ko       []string
+		apko     []string
+	}{
+		{
+			// An out-of-order patch only moves its minor line.
+			version: "0.88.2",
+			manifest: []string{
+				image + ":{{ .Version }}",
+				image + ":{{ .Major }}.{{ .Minor }}",
+			},
+			ko: []string{
+				"{{ .Version }}",
+				"{{ if not .Prerelease }}{{ .Major }}.{{ .Minor }}{{ end }}",
+			},
+			apko: []string{
+				image + ":0.88.2",
+				image + ":0.88",
+			},
+		},
+		{
+			version: "0.89.1",
+			manifest: []string{
+				image + ":{{ .Version }}",
+				image + ":latest",
+				image + ":{{ .Major }}.{{ .Minor }}",
+				image + ":{{ .Major }}",
+			},
+			ko: []string{
+				"{{ .Version }}",
+				"latest",
+				"{{ if not .Prerelease }}{{ .Major }}.{{ .Minor }}{{ end }}",
+				"{{ if not .Prerelease }}{{ .Major }}{{ end }}",
+			},
+			apko: []string{
+				image + ":0.89.1",
+				image + ":latest",
+				image + ":0.89",
+				image + ":0",
+			},
+		},
+		{
+			// Prereleases never move the release lines.
+			version: "0.90.0-rc.2",
+			manifest: []string{
+				image + ":{{ .Version }}",
+				image + ":latest",
+			},
+			ko: []string{
+				"{{ .Version }}",
+				"latest",
+			},
+			apko: []string{
+				image + ":0.90.0-rc.2",
+				image + ":latest",
+			},
+		},
+	} {
+		t.Run(tc.version, func(t *testing.T) {
+			dist := Distribution{
+				ID:   "otelcol",
+				Dist: DistConfig{Name: "otelcol", Version: tc.version},
+			}
+
+			var manifest []string
+			for _, m := range DockerManifests([]string{prefix}, []Distribution{dist}, releases) {
+				manifest = append(manifest, m.NameTemplate)
+			}
+			if !reflect.DeepEqual(manifest, tc.manifest) {
+				t.Errorf("DockerManifests() = %q, want %q", manifest, tc.manifest)
+			}
+
+			dist.Release.Docker.Ko = true
+			if ko := KoImage("otelcol-ko-0", prefix, dist, releases).Tags; !reflect.DeepEqual(ko, tc.ko) {
+				t.Errorf("KoImage() tags = %q, want %q", ko, tc.ko)
+			}
+
+			if apko := ApkoImageTags([]string{prefix}, dist, "", releases); !reflect.DeepEqual(apko, tc.apko) {
+				t.Errorf("ApkoImageTags() = %q, want %q", apko, tc.apko)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"strings"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// DebugID is the ID of the build and archives of the distribution's
+// unstripped binaries.
+func (d Distribution) DebugID() string {
+	return d.ID + "-debug"
+}
+
+// DebugBuild configures the build of the distribution's binaries with their
+// symbol table and DWARF, which the others are stripped of. Go builds being
+// reproducible, the code is the one of the stripped binaries, so that their
+// crashes can be symbolized.
+func DebugBuild(dist Distribution) config.Build {
+	b := Build(dist)
+	b.ID = dist.DebugID()
+	b.Binary = dist.BinaryName() + ".debug"
+	var ldflags []string
+	for _, flag := range b.Ldflags {
+		if flag != "-s" && flag != "-w" {
+			ldflags = append(ldflags, flag)
+		}
+	}
+	b.Ldflags = ldflags
+	b.BuildDetailsOverrides = targetOverrides(dist, b, cgoCompilers(dist))
+	return b
+}
+
+// DebugArchive configures the archives of the unstripped binaries, such as
+// otelcol-debug_0.89.0_linux_amd64.tar.gz.
+func DebugArchive(dist Distribution) config.Archive {
+	return config.Archive{
+		ID:           dist.DebugID(),
+		NameTemplate: strings.Replace(archiveNameTemplate, "{{ .Binary }}", dist.BinaryName()+"-debug", 1),
+		Builds:       []string{dist.DebugID()},
+		BuildsInfo:   config.FileInfo{MTime: commitDate},
+		Files:        archiveFiles(),
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DeltaDir is the directory the delta patches are written to, attached to
+// the release.
+const DeltaDir = "_deltas"
+
+// DeltaPatch is a binary patch upgrading the binary of a distribution from
+// one release to the next on a given platform.
+type DeltaPatch struct {
+	Dist       string
+	Platform   string
+	OldVersion string
+	NewVersion string
+	Path       string
+}
+
+// releaseArchive is a version-named archive of a distribution.
+type releaseArchive struct {
+	version  string
+	platform string
+	path     string
+}
+
+// WriteDeltas writes a zstd patch from the binary of the previous release
+// to the new one, for every platform of the given distributions found in
+// both oldDir and newDir. The archives are searched recursively, and the
+// binaries are read from them, as the binaries aren't released on their own.
+func WriteDeltas(oldDir, newDir string, dists []Distribution) ([]DeltaPatch, error) {
+	if err := os.MkdirAll(DeltaDir, 0o755); err != nil {
+		return nil, err
+	}
+	patches, err := forEach(dists, func(dist Distribution) ([]DeltaPatch, error) {
+		return writeDeltas(oldDir, newDir, dist)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var r []DeltaPatch
+	for _, p := range patches {
+		r = append(r, p...)
+	}
+	return r, nil
+}
+
+func writeDeltas(oldDir, newDir string, dist Distribution) ([]DeltaPatch, error) {
+	oldArchives, err := findArchives(oldDir, dist)
+	if err != nil {
+		return nil, err
+	}
+	newArchives, err := findArchives(newDir, dist)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.MkdirTemp("", "deltas-"+dist.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	var platforms []string
+	for platform := range newArchives {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	var r []DeltaPatch
+	for _, platform := range platforms {
+		newArchive := newArchives[platform]
+		oldArchive, ok := oldArchives[platform]
+		if !ok || oldArchive.version == newArchive.version {
+			continue
+		}
+		oldBinary := filepath.Join(tmp, fmt.Sprintf("%s_%s", oldArchive.version, oldArchive.platform))
+		if err := extractBinary(oldArchive.path, dist.BinaryName(), oldBinary); err != nil {
+			return nil, err
+		}
+		newBinary := filepath.Join(tmp, fmt.Sprintf("%s_%s", newArchive.version, newArchive.platform))
+		if err := extractBinary(newArchive.path, dist.BinaryName(), newBinary); err != nil {
+			return nil, err
+		}
+
+		patch := DeltaPatch{
+			Dist:       dist.ID,
+			Platform:   newArchive.platform,
+			OldVersion: oldArchive.version,
+			NewVersion: newArchive.version,
+			Path:       path.Join(DeltaDir, fmt.Sprintf("%s_%s_to_%s_%s.patch.zst", dist.BinaryName(), oldArchive.version, newArchive.version, newArchive.platform)),
+		}
+		// --long is needed for the previous binary to fit in the window of
+		// the patch, and makes applying it need the same flag.
+		cmd := exec.Command("zstd", "-q", "-f", "-19", "--long=31", "--patch-from="+oldBinary, newBinary, "-o", patch.Path)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("failed to generate the patch %s: %w: %s", patch.Path, err, strings.TrimSpace(string(out)))
+		}
+		r = append(r, patch)
+	}
+	return r, nil
+}
+
+// DownloadReleaseArchives downloads the archives of the distribution
+// published with the release of tag to dir, with the GitHub CLI. It returns
+// false when the release has none, such as for a distribution added since,
+// or when the release doesn't exist.
+func DownloadReleaseArchives(tag, dir string, dist Distribution) (bool, error) {
+	cmd := exec.Command("gh", "release", "download", tag, "--dir", dir, "--skip-existing", "--pattern", dist.BinaryName()+"_*.tar.gz")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		return true, nil
+	}
+	if msg := string(out); strings.Contains(msg, "no assets match") || strings.Contains(msg, "release not found") {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to download the archives of distribution %q from the release %s: %w: %s", dist.ID, tag, err, strings.TrimSpace(string(out)))
+}
+
+// findArchives returns the archives of the distribution found under dir,
+// named <binary>_<version>_<platform>.tar.gz, by platform. The version-less
+// aliases of the latest release are left out. A missing dir has none.
+func findArchives(dir string, dist Distribution) (map[string]releaseArchive, error) {
+	prefix := dist.BinaryName() + "_"
+	r := map[string]releaseArchive{}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return r, nil
+	}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		name := d.Name()
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".tar.gz") {
+			return nil
+		}
+		version, platform, ok := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".tar.gz"), "_")
+		if !ok || version == "latest" {
+			return nil
+		}
+		r[platform] = releaseArchive{version: version, platform: platform, path: p}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look for the archives of distribution %q in %s: %w", dist.ID, dir, err)
+	}
+	return r, nil
+}
+
+// extractBinary writes the binary of the given archive to dest.
+func extractBinary(archive, binary, dest string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", archive, err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return fmt.Errorf("no %s binary in %s", binary, archive)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", archive, err)
+		}
+		if name := path.Base(hdr.Name); name != binary && name != binary+".exe" {
+			continue
+		}
+		out, err := os.Create(dest)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, tr); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ImageDigests are the files listing the image manifests pushed by a release
+// and covering them with its checksums file.
+type ImageDigests struct {
+	// File lists the image manifests, one <image>:<tag>@<digest> per line.
+	File string
+	// Checksums is goreleaser's checksums file, to which the checksum of File
+	// was added.
+	Checksums string
+}
+
+// WriteImageDigests reads the release published by goreleaser from distDir,
+// lists the image manifests of the given distributions it pushed in
+// <project>_image-digests.txt, and adds the checksum of the list to the
+// checksums file. goreleaser computes the checksums before pushing the
+// images, so the digests can't be covered any earlier. It writes nothing when
+// no image was pushed.
+func WriteImageDigests(distDir string, dists []Distribution) (ImageDigests, error) {
+	var metadata releaseMetadata
+	if err := readJSON(filepath.Join(distDir, "metadata.json"), &metadata); err != nil {
+		return ImageDigests{}, err
+	}
+	var artifacts []releaseArtifact
+	if err := readJSON(filepath.Join(distDir, "artifacts.json"), &artifacts); err != nil {
+		return ImageDigests{}, err
+	}
+
+	var checksums string
+	var lines []string
+	for _, a := range artifacts {
+		switch a.Type {
+		case "Checksum":
+			checksums = a.Path
+		case "Docker Manifest":
+			repository, _, _ := strings.Cut(a.Name, ":")
+			if a.Extra.Digest == "" {
+				continue
+			}
+			for _, dist := range dists {
+				if path.Base(repository) == imageName(dist) {
+					lines = append(lines, a.Name+"@"+a.Extra.Digest)
+					break
+				}
+			}
+		}
+	}
+	if checksums == "" {
+		return ImageDigests{}, fmt.Errorf("no checksums file found in %s", distDir)
+	}
+	if len(lines) == 0 {
+		return ImageDigests{}, nil
+	}
+	sort.Strings(lines)
+
+	r := ImageDigests{
+		File:      filepath.Join(distDir, metadata.ProjectName+"_image-digests.txt"),
+		Checksums: checksums,
+	}
+	b := []byte(strings.Join(lines, "\n") + "\n")
+	if err := os.WriteFile(r.File, b, 0o644); err != nil {
+		return ImageDigests{}, err
+	}
+	return r, addChecksum(checksums, filepath.Base(r.File), checksum(b))
+}
+
+// addChecksum adds the checksum of a file to a checksums file in the format
+// of sha256sum, replacing the one of the same name, sorted by name like
+// goreleaser's.
+func addChecksum(checksums, name, sum string) error {
+	b, err := os.ReadFile(checksums)
+	if err != nil {
+		return err
+	}
+	sums := map[string]string{name: sum}
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		if s, n, ok := strings.Cut(line, "  "); ok && n != name {
+			sums[n] = s
+		}
+	}
+	names := make([]string, 0, len(sums))
+	for n := range sums {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	for _, n := range names {
+		fmt.Fprintf(&sb, "%s  %s\n", sums[n], n)
+	}
+	return os.WriteFile(checksums, []byte(sb.String()), 0o644)
+}
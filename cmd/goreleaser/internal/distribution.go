@@ -0,0 +1,796 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Distribution is a collector distribution, as declared by the manifest.yaml
+// file within its directory under distributions/.
+type Distribution struct {
+	// ID is the name of the distribution's directory.
+	ID string `yaml:"-"`
+
+	Dist    DistConfig    `yaml:"dist"`
+	Release ReleaseConfig `yaml:"release"`
+
+	// The other sections are the ones of ocb, modelled so that the manifest
+	// can be parsed strictly.
+	Receivers  []Module `yaml:"receivers"`
+	Exporters  []Module `yaml:"exporters"`
+	Extensions []Module `yaml:"extensions"`
+	Processors []Module `yaml:"processors"`
+	Connectors []Module `yaml:"connectors"`
+	Replaces   []string `yaml:"replaces"`
+	Excludes   []string `yaml:"excludes"`
+}
+
+// DistConfig is the "dist" section of the manifest, shared with ocb.
+type DistConfig struct {
+	Module           string `yaml:"module"`
+	Name             string `yaml:"name"`
+	Go               string `yaml:"go"`
+	Description      string `yaml:"description"`
+	Version          string `yaml:"version"`
+	OtelColVersion   string `yaml:"otelcol_version"`
+	OutputPath       string `yaml:"output_path"`
+	BuildTags        string `yaml:"build_tags"`
+	DebugCompilation bool   `yaml:"debug_compilation"`
+}
+
+// Module is a component of the distribution, as declared in the ocb
+// manifest.
+type Module struct {
+	// GoMod is the module providing the component, as "<module> <version>".
+	GoMod  string `yaml:"gomod"`
+	Import string `yaml:"import"`
+	Name   string `yaml:"name"`
+	Path   string `yaml:"path"`
+}
+
+// ReleaseConfig holds the settings only used when generating the release
+// pipeline for the distribution. ocb ignores this section.
+type ReleaseConfig struct {
+	Branding Branding     `yaml:"branding"`
+	Metadata Metadata     `yaml:"metadata"`
+	Docker   DockerConfig `yaml:"docker"`
+
+	// ConfigVariants are alternative default configurations, such as "agent"
+	// and "gateway", read from configs/<dist>-<variant>.yaml. Each variant is
+	// installed by the packages and published as its own image tag.
+	ConfigVariants []string `yaml:"config_variants"`
+
+	// EmbedConfig embeds configs/<dist>.yaml into the binary, which uses it
+	// when started without a --config flag.
+	EmbedConfig bool `yaml:"embed_config"`
+
+	// ExtraFiles are additional assets attached to the GitHub release, such
+	// as config schemas or dashboards. Globs are relative to the repository.
+	ExtraFiles []config.ExtraFile `yaml:"extra_files"`
+
+	// Uploads publish the distribution's archives and packages to HTTP
+	// endpoints, such as Nexus raw repositories. Unless set, the IDs are the
+	// ones of the distribution's artifacts.
+	Uploads []config.Upload `yaml:"uploads"`
+
+	// BuildVariants are alternative builds of the distribution, generated
+	// from the same manifest.
+	BuildVariants []BuildVariant `yaml:"build_variants"`
+
+	// ExtraTargets are platforms the distribution opts in to on top of the
+	// default ones, as <goos>/<goarch>, such as "linux/mips64le" or
+	// "aix/ppc64". They must be listed in OptInTargets, and get binaries,
+	// archives and packages, but no images.
+	ExtraTargets []string `yaml:"extra_targets"`
+
+	// ExcludeTargets leave default platforms out of the distribution's
+	// builds, archives, packages and images, as <goos>/<goarch>, such as
+	// "windows/386", or as linux/arm/v<goarm> for a single arm version.
+	ExcludeTargets []string `yaml:"exclude_targets"`
+
+	// Gomips are the GOMIPS variants of the MIPS targets, "hardfloat" and/or
+	// "softfloat". Defaults to "hardfloat".
+	Gomips []string `yaml:"gomips"`
+
+	// Cgo builds the distribution with cgo, for components needing it, such
+	// as some SQL drivers. Only the targets with a C cross-compiler in
+	// CgoCompilers are built.
+	Cgo bool `yaml:"cgo"`
+
+	// FIPS adds a build compiled with Go's BoringCrypto module, for linux
+	// amd64 and arm64, released as <binary>-fips archives and <version>-fips
+	// images.
+	FIPS bool `yaml:"fips"`
+
+	// Musl adds a build of a cgo distribution linked statically against
+	// musl, for the linux targets in MuslCompilers, released as
+	// <binary>-musl archives.
+	Musl bool `yaml:"musl"`
+
+	// DebugSymbols adds a build of the distribution's binaries with their
+	// symbol table and DWARF, released as <binary>-debug archives, to
+	// symbolize the crashes of the stripped ones.
+	DebugSymbols bool `yaml:"debug_symbols"`
+
+	// Goos restricts the operating systems the distribution is built for,
+	// among OperatingSystems, such as "linux" only. Defaults to all of them.
+	Goos []string `yaml:"goos"`
+
+	// Goarch restricts the architectures the distribution is built for,
+	// among Architectures, such as "amd64" and "arm64" only. Defaults to all
+	// of them. Extra targets are built regardless.
+	Goarch []string `yaml:"goarch"`
+
+	// Goamd64 are the GOAMD64 levels of the amd64 builds, such as "v1" and
+	// "v3". They must include v1, which the images in the manifests use, and
+	// default to it only. The other levels get their own archives and image
+	// tags, suffixed with the level.
+	Goamd64 []string `yaml:"goamd64"`
+
+	// ArmVersions are the GOARM versions to build for, such as "6" and "7",
+	// among ArmVersions and OptInArmVersions. Defaults to the ArmVersions
+	// package var.
+	ArmVersions []string `yaml:"arm_versions"`
+
+	// UniversalBinary publishes a darwin universal binary, merging the amd64
+	// and arm64 ones, in an archive of its own next to theirs. goreleaser
+	// only merges the binaries built by the same job, so the release is then
+	// split by GOOS instead of by target.
+	UniversalBinary bool `yaml:"universal_binary"`
+
+	// OpAMPSupervisor bundles the OpAMP supervisor released with the
+	// collector in the distribution's archives and packages, and publishes an
+	// image running the supervisor managing the collector.
+	OpAMPSupervisor bool `yaml:"opamp_supervisor"`
+
+	// GoVersion pins the Go toolchain used to compile the distribution, such
+	// as "1.21.4". Defaults to the toolchain running goreleaser.
+	GoVersion string `yaml:"go_version"`
+
+	// Env are extra environment variables of the distribution's builds, as
+	// KEY=VALUE, such as "GOEXPERIMENT=loopvar" or "GOFLAGS=-mod=mod". The
+	// variables set by the generator itself, ReservedEnv, can't be
+	// overridden.
+	Env []string `yaml:"env"`
+
+	// Hooks are commands run before and after every build of the
+	// distribution, once per target, such as "go mod verify" or a check of
+	// the binary. A failing hook fails the release. Unless set, the directory
+	// of the pre hooks is the build's, with the generated sources, and the
+	// one of the post hooks the repository.
+	// https://goreleaser.com/customization/build/#build-hooks
+	Hooks config.BuildHookConfig `yaml:"hooks"`
+
+	// Prebuilt imports the distribution's binaries from this directory,
+	// relative to the repository, instead of compiling them, for targets
+	// built elsewhere such as on native s390x hardware. The binaries are
+	// laid out as in goreleaser's dist directory, and still archived,
+	// packaged and put in images.
+	Prebuilt string `yaml:"prebuilt"`
+
+	// Overrides add build settings to some targets of the distribution, such
+	// as linker flags for windows or build tags for arm.
+	Overrides []BuildOverride `yaml:"overrides"`
+}
+
+// BuildVariant is an alternative build of a distribution, such as one without
+// some components. It produces separately-named binaries, archives and image
+// tags, suffixed by the variant name.
+type BuildVariant struct {
+	Name string `yaml:"name"`
+	// ExcludeComponents are the Go modules of the components of the
+	// distribution left out of the variant.
+	ExcludeComponents []string `yaml:"exclude_components"`
+	// Tags are Go build tags used when compiling the variant.
+	Tags []string `yaml:"tags"`
+}
+
+// Branding allows vendors to release a white-labelled collector from this
+// pipeline. Every field is optional and defaults to the upstream naming.
+type Branding struct {
+	DisplayName string `yaml:"display_name"`
+	ServiceName string `yaml:"service_name"`
+	SupportURL  string `yaml:"support_url"`
+	BinaryName  string `yaml:"binary_name"`
+}
+
+// Metadata describes the distribution consistently across packages and
+// images. Every field is optional and defaults to the upstream values.
+type Metadata struct {
+	Description string `yaml:"description"`
+	Homepage    string `yaml:"homepage"`
+	Maintainer  string `yaml:"maintainer"`
+	// License is an SPDX license expression.
+	License string `yaml:"license"`
+	// Vendor is the organization distributing the collector.
+	Vendor string `yaml:"vendor"`
+	// Documentation is the URL of the distribution's documentation.
+	Documentation string `yaml:"documentation"`
+}
+
+// DockerConfig customizes the distribution's container images.
+type DockerConfig struct {
+	// APKPackages and APTPackages are OS packages to install in the image,
+	// based on Alpine or Debian respectively instead of scratch.
+	APKPackages []string `yaml:"apk_packages"`
+	APTPackages []string `yaml:"apt_packages"`
+
+	// CustomDockerfile keeps the distribution's Dockerfile written by hand
+	// instead of rendering it from the shared template. It is checked
+	// against the images goreleaser builds with it.
+	CustomDockerfile bool `yaml:"custom_dockerfile"`
+
+	// BundledConfigs are configs copied into every image next to the default
+	// one, such as "agent" and "gateway", read from
+	// configs/<dist>-<name>.yaml and copied to /etc/<dist>/<name>.yaml, so
+	// that users select one with --config instead of pulling another image.
+	BundledConfigs []string `yaml:"bundled_configs"`
+
+	// HealthCheck wires the health_check extension into the images: its port,
+	// HealthCheckPort, is exposed for the liveness and readiness probes of
+	// orchestration platforms, and the images with a shell probe it with a
+	// HEALTHCHECK. The distribution must include the extension, and every
+	// config of its images must enable it.
+	HealthCheck bool `yaml:"health_check"`
+
+	// UID and GID are the user and group the images run as, passed to the
+	// Dockerfiles as the USER_UID and USER_GID build arguments. The UID
+	// defaults to DefaultUID, as images can't run as root. The GID defaults
+	// to the root group, which OpenShift runs containers with under an
+	// arbitrary UID, so the state directory is writable by the group.
+	UID int `yaml:"uid"`
+	GID int `yaml:"gid"`
+
+	// AssetVariants are images bundling auxiliary assets on top of the
+	// distribution's image, such as the JMX metrics gatherer used by the
+	// jmx receiver.
+	AssetVariants []AssetVariant `yaml:"asset_variants"`
+
+	// Distroless publishes an additional image, tagged <version>-distroless,
+	// holding the binary, its config and the CA certificates only, without
+	// a shell nor the OS packages of the default image. Its Dockerfile is
+	// rendered to Dockerfile.distroless.
+	Distroless bool `yaml:"distroless"`
+
+	// UBI publishes an additional image, tagged <version>-ubi, based on the
+	// Red Hat Universal Base Image for the platforms it supports, to satisfy
+	// the base image policies of OpenShift clusters. Its Dockerfile is
+	// rendered to Dockerfile.ubi.
+	UBI bool `yaml:"ubi"`
+
+	// Apko builds the images with melange and apko on Wolfi instead of
+	// docker buildx: the generator writes their declarative configurations to
+	// _apko, and `make apko-images` builds and publishes them, with their
+	// SBOMs. The images of the config variants are built too, but not the
+	// other image flavors.
+	Apko bool `yaml:"apko"`
+
+	// Ko builds the default image with ko instead of docker buildx, from the
+	// distribution's build onto KoBaseImage, without a Dockerfile. The
+	// images are pushed with their SBOMs by goreleaser's publish step. The
+	// config must be embedded into the binary, as ko copies nothing else,
+	// and the other image flavors and Dockerfile settings aren't supported.
+	Ko bool `yaml:"ko"`
+
+	// KoBaseImage is the base image of the ko images, KoBaseImageRepository
+	// pinned by digest, such as "cgr.dev/chainguard/static@sha256:...", so
+	// that the images are reproducible. Required with Ko.
+	KoBaseImage string `yaml:"ko_base_image"`
+
+	// SkipLatest leaves the latest tags out of the images, for pre-GA or
+	// experimental distributions whose images users shouldn't get by
+	// default.
+	SkipLatest bool `yaml:"skip_latest"`
+
+	// BuildArgs are build arguments passed to every image of the
+	// distribution, as NAME=VALUE, such as "BASE_IMAGE=alpine:3.19", so
+	// that a single parameterized Dockerfile serves several variants. The
+	// values can be goreleaser templates. The arguments set by the generator
+	// itself, ReservedBuildArgs, can't be overridden.
+	BuildArgs []string `yaml:"build_args"`
+
+	// Hermetic compiles the binary of the images within their Dockerfile,
+	// from the generated sources and with a pinned Go image, instead of
+	// copying the binary built on the host. The Dockerfile is rendered to
+	// Dockerfile.hermetic.
+	Hermetic bool `yaml:"hermetic"`
+
+	// Platforms restrict the images to a subset of the platforms binaries are
+	// built for, as docker platforms such as "linux/amd64" or "linux/arm/v7".
+	// Defaults to every image architecture.
+	Platforms []string `yaml:"platforms"`
+
+	// Ports are the ports exposed by the image. Defaults to the ExposedPorts
+	// package var.
+	Ports []string `yaml:"ports"`
+
+	// Windows are the Windows Server releases to publish windows/amd64
+	// images for, among WindowsVersions, such as "ltsc2022". They are listed
+	// in the multi-arch manifests of the default image, and their Dockerfile
+	// is rendered to Dockerfile.windows.
+	Windows []string `yaml:"windows"`
+
+	// WindowsBaseImage is the base image of the Windows images, among
+	// WindowsBaseImages. Defaults to nanoserver.
+	WindowsBaseImage string `yaml:"windows_base_image"`
+}
+
+// AssetVariant is an image of the distribution bundling auxiliary assets,
+// tagged with the variant name.
+type AssetVariant struct {
+	Name   string  `yaml:"name"`
+	Assets []Asset `yaml:"assets"`
+}
+
+// Asset is a file downloaded when generating the sources and copied into an
+// asset variant's image.
+type Asset struct {
+	URL string `yaml:"url"`
+	// SHA256 is the expected checksum of the downloaded file.
+	SHA256 string `yaml:"sha256"`
+	// Path is the absolute path of the asset in the image.
+	Path string `yaml:"path"`
+}
+
+// LoadDistributions reads the manifests for the given distributions.
+func LoadDistributions(dists []string) ([]Distribution, error) {
+	return forEach(dists, LoadDistribution)
+}
+
+// LoadDistribution reads distributions/<dist>/manifest.yaml.
+func LoadDistribution(dist string) (Distribution, error) {
+	manifest := path.Join("distributions", dist, "manifest.yaml")
+	b, err := os.ReadFile(manifest)
+	if err != nil {
+		return Distribution{}, fmt.Errorf("failed to read manifest for distribution %q: %w", dist, err)
+	}
+
+	// Unknown fields are errors, so that typos don't silently fall back to
+	// the defaults.
+	d := Distribution{ID: dist}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&d); err != nil {
+		return Distribution{}, fmt.Errorf("failed to parse %s: %w", manifest, err)
+	}
+	for _, target := range d.Release.ExtraTargets {
+		if !contains(OptInTargets, target) {
+			return Distribution{}, fmt.Errorf("unsupported extra target %q in %s, expected one of %s", target, manifest, strings.Join(OptInTargets, ", "))
+		}
+	}
+	// The supervisor starts the collector as a process, which WASI doesn't
+	// support.
+	if d.Release.OpAMPSupervisor && contains(d.Release.ExtraTargets, WASIPTarget) {
+		return Distribution{}, fmt.Errorf("distributions bundling the OpAMP supervisor can't target %s in %s", WASIPTarget, manifest)
+	}
+	for _, goos := range d.Release.Goos {
+		if !contains(OperatingSystems, goos) {
+			return Distribution{}, fmt.Errorf("unsupported goos %q in %s, expected one of %s", goos, manifest, strings.Join(OperatingSystems, ", "))
+		}
+	}
+	for _, goarch := range d.Release.Goarch {
+		if !contains(Architectures, goarch) {
+			return Distribution{}, fmt.Errorf("unsupported goarch %q in %s, expected one of %s", goarch, manifest, strings.Join(Architectures, ", "))
+		}
+	}
+	if d.Release.UniversalBinary {
+		for _, arch := range []string{"amd64", "arm64"} {
+			if !contains(d.Goos(), "darwin") || !contains(d.Goarch(), arch) || contains(d.Release.ExcludeTargets, "darwin/"+arch) {
+				return Distribution{}, fmt.Errorf("universal binaries require darwin/amd64 and darwin/arm64 builds in %s", manifest)
+			}
+		}
+		// No C cross-compiler targets darwin.
+		if d.Release.Cgo {
+			return Distribution{}, fmt.Errorf("cgo distributions can't have universal binaries in %s", manifest)
+		}
+	}
+	if d.Release.Musl && !d.Release.Cgo {
+		return Distribution{}, fmt.Errorf("musl builds require cgo in %s", manifest)
+	}
+	if d.Release.Cgo {
+		for _, target := range d.Release.ExtraTargets {
+			if CgoCompilers[target] == "" {
+				return Distribution{}, fmt.Errorf("extra target %q in %s has no C cross-compiler for cgo", target, manifest)
+			}
+		}
+		// The binaries link against glibc, which neither the alpine base
+		// image nor the hermetic build stage provide.
+		if len(d.Release.Docker.APKPackages) > 0 || d.Release.Docker.Hermetic {
+			return Distribution{}, fmt.Errorf("cgo distributions can't have apk packages or hermetic images in %s", manifest)
+		}
+		// Nor does the empty base of the distroless images, nor the one of
+		// the rendered Dockerfile without apt packages.
+		if d.Release.Docker.Distroless {
+			return Distribution{}, fmt.Errorf("cgo distributions can't have distroless images in %s", manifest)
+		}
+		if len(d.Release.Docker.APTPackages) == 0 && !d.Release.Docker.CustomDockerfile && !d.Release.Docker.Apko {
+			return Distribution{}, fmt.Errorf("cgo distributions need apt_packages or a custom_dockerfile for a glibc base image in %s", manifest)
+		}
+	}
+	// The hermetic images compile the binary without cgo, which BoringCrypto
+	// requires.
+	if d.Release.FIPS && d.Release.Docker.Hermetic {
+		return Distribution{}, fmt.Errorf("FIPS distributions can't have hermetic images in %s", manifest)
+	}
+	for _, env := range d.Release.Env {
+		if err := validateEnv(env); err != nil {
+			return Distribution{}, fmt.Errorf("unsupported env %q in %s: %w", env, manifest, err)
+		}
+	}
+	if d.Release.Docker.UID < 0 || d.Release.Docker.GID < 0 {
+		return Distribution{}, fmt.Errorf("invalid image user %d:%d in %s", d.Release.Docker.UID, d.Release.Docker.GID, manifest)
+	}
+	for i, name := range d.Release.Docker.BundledConfigs {
+		if name == "" || name == "config" || strings.ContainsAny(name, `/\`) || contains(d.Release.Docker.BundledConfigs[:i], name) {
+			return Distribution{}, fmt.Errorf("invalid bundled config %q in %s", name, manifest)
+		}
+	}
+	if d.Release.Docker.HealthCheck && !includesHealthCheck(d) {
+		return Distribution{}, fmt.Errorf("health checks require the %s extension in %s", HealthCheckModule, manifest)
+	}
+	if d.Release.Docker.CustomDockerfile && (len(d.Release.Docker.APKPackages) > 0 || len(d.Release.Docker.APTPackages) > 0) {
+		return Distribution{}, fmt.Errorf("custom Dockerfiles can't declare OS packages in %s", manifest)
+	}
+	for _, arg := range d.Release.Docker.BuildArgs {
+		if err := validateBuildArg(arg); err != nil {
+			return Distribution{}, fmt.Errorf("unsupported build arg %q in %s: %w", arg, manifest, err)
+		}
+	}
+	for _, o := range d.Release.Overrides {
+		if len(o.Targets) == 0 {
+			return Distribution{}, fmt.Errorf("overrides need targets in %s", manifest)
+		}
+		for _, target := range o.Targets {
+			if err := validateOverrideTarget(target); err != nil {
+				return Distribution{}, fmt.Errorf("unsupported override target %q in %s: %w", target, manifest, err)
+			}
+		}
+		for _, env := range o.Env {
+			if err := validateEnv(env); err != nil {
+				return Distribution{}, fmt.Errorf("unsupported override env %q in %s: %w", env, manifest, err)
+			}
+		}
+	}
+	for _, version := range d.Release.Docker.Windows {
+		if !contains(WindowsVersions, version) {
+			return Distribution{}, fmt.Errorf("unsupported Windows image version %q in %s, expected one of %s", version, manifest, strings.Join(WindowsVersions, ", "))
+		}
+	}
+	if base := d.Release.Docker.WindowsBaseImage; base != "" && WindowsBaseImages[base] == "" {
+		return Distribution{}, fmt.Errorf("unsupported Windows base image %q in %s, expected nanoserver or servercore", base, manifest)
+	}
+	if d.Release.Docker.UBI && !containsAny(d.Goarch(), UBIArchitectures) {
+		return Distribution{}, fmt.Errorf("UBI images require a build for one of %s in %s", strings.Join(UBIArchitectures, ", "), manifest)
+	}
+	if d.Release.Docker.Apko {
+		if len(ImageVariants(d)) > len(ApkoImages(d)) || len(d.Release.Docker.Windows) > 0 {
+			return Distribution{}, fmt.Errorf("apko images don't support the other image flavors in %s", manifest)
+		}
+		if !containsAny(d.Goarch(), ApkoArchitectures) {
+			return Distribution{}, fmt.Errorf("apko images require a build for one of %s in %s", strings.Join(ApkoArchitectures, ", "), manifest)
+		}
+	}
+	if d.Release.Docker.KoBaseImage != "" && !d.Release.Docker.Ko {
+		return Distribution{}, fmt.Errorf("ko_base_image requires ko in %s", manifest)
+	}
+	if d.Release.Docker.Ko {
+		docker := d.Release.Docker
+		if len(ImageVariants(d)) > 1 || len(docker.Windows) > 0 || docker.Apko || docker.Hermetic || docker.CustomDockerfile ||
+			len(docker.APKPackages) > 0 || len(docker.APTPackages) > 0 || len(docker.BundledConfigs) > 0 || len(docker.BuildArgs) > 0 {
+			return Distribution{}, fmt.Errorf("ko images don't support the other image flavors nor Dockerfile settings in %s", manifest)
+		}
+		// The base image runs as its own nonroot user.
+		if docker.UID != 0 || docker.GID != 0 {
+			return Distribution{}, fmt.Errorf("ko images can't set their user in %s", manifest)
+		}
+		if !d.Release.EmbedConfig {
+			return Distribution{}, fmt.Errorf("ko images require embed_config in %s", manifest)
+		}
+		if !koBaseImageRe.MatchString(docker.KoBaseImage) {
+			return Distribution{}, fmt.Errorf("ko images require a ko_base_image of %s pinned by digest in %s", KoBaseImageRepository, manifest)
+		}
+		if !containsAny(d.Goarch(), ApkoArchitectures) {
+			return Distribution{}, fmt.Errorf("ko images require a build for one of %s in %s", strings.Join(ApkoArchitectures, ", "), manifest)
+		}
+		// The static base image has no libc.
+		if d.Release.Cgo {
+			return Distribution{}, fmt.Errorf("cgo distributions can't have ko images in %s", manifest)
+		}
+	}
+	if len(d.Release.Docker.Windows) > 0 && (!contains(d.Goos(), "windows") || !contains(d.Goarch(), "amd64") || contains(d.Release.ExcludeTargets, "windows/amd64")) {
+		return Distribution{}, fmt.Errorf("Windows images require a windows/amd64 build in %s", manifest)
+	}
+	for _, target := range d.Release.ExcludeTargets {
+		if err := validateExcludeTarget(target); err != nil {
+			return Distribution{}, fmt.Errorf("unsupported excluded target %q in %s: %w", target, manifest, err)
+		}
+	}
+	for _, level := range d.Release.Goamd64 {
+		if !contains(Amd64Levels, level) {
+			return Distribution{}, fmt.Errorf("unsupported goamd64 %q in %s, expected one of %s", level, manifest, strings.Join(Amd64Levels, ", "))
+		}
+	}
+	if len(d.Release.Goamd64) > 0 && !contains(d.Release.Goamd64, "v1") {
+		return Distribution{}, fmt.Errorf("goamd64 must include v1 in %s", manifest)
+	}
+	for _, gomips := range d.Release.Gomips {
+		if gomips != "hardfloat" && gomips != "softfloat" {
+			return Distribution{}, fmt.Errorf("unsupported gomips %q in %s, expected hardfloat or softfloat", gomips, manifest)
+		}
+	}
+	armVersions := append(append([]string{}, ArmVersions...), OptInArmVersions...)
+	for _, armVersion := range d.Release.ArmVersions {
+		if !contains(armVersions, armVersion) {
+			return Distribution{}, fmt.Errorf("unsupported arm version %q in %s, expected one of %s", armVersion, manifest, strings.Join(armVersions, ", "))
+		}
+	}
+	var platforms []string
+	for _, p := range supportedImagePlatforms(d) {
+		platforms = append(platforms, p.String())
+	}
+	for _, platform := range d.Release.Docker.Platforms {
+		if !contains(platforms, platform) {
+			return Distribution{}, fmt.Errorf("unsupported image platform %q in %s, expected one of %s", platform, manifest, strings.Join(platforms, ", "))
+		}
+	}
+	return d, nil
+}
+
+// validateEnv checks that an env entry is KEY=VALUE and doesn't override a
+// variable set by the generator.
+func validateEnv(env string) error {
+	key, _, ok := strings.Cut(env, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected KEY=VALUE")
+	}
+	if contains(ReservedEnv, key) {
+		return fmt.Errorf("%s is set by the generator", key)
+	}
+	return nil
+}
+
+// validateBuildArg checks that a build argument is NAME=VALUE and doesn't
+// override an argument set by the generator.
+func validateBuildArg(arg string) error {
+	name, _, ok := strings.Cut(arg, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("expected NAME=VALUE")
+	}
+	if contains(ReservedBuildArgs, name) {
+		return fmt.Errorf("%s is set by the generator", name)
+	}
+	return nil
+}
+
+// validateExcludeTarget checks that an excluded target is one of the default
+// platforms, as <goos>/<goarch> or <goos>/arm/v<goarm>.
+func validateExcludeTarget(target string) error {
+	parts := strings.Split(target, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return fmt.Errorf("expected <goos>/<goarch> or <goos>/arm/v<goarm>")
+	}
+	if !contains(OperatingSystems, parts[0]) {
+		return fmt.Errorf("expected one of the %s operating systems", strings.Join(OperatingSystems, ", "))
+	}
+	if !contains(Architectures, parts[1]) {
+		return fmt.Errorf("expected one of the %s architectures", strings.Join(Architectures, ", "))
+	}
+	if len(parts) == 3 {
+		armVersions := append(append([]string{}, ArmVersions...), OptInArmVersions...)
+		if parts[1] != ArmArch || !strings.HasPrefix(parts[2], "v") || !contains(armVersions, strings.TrimPrefix(parts[2], "v")) {
+			return fmt.Errorf("expected an arm version, such as arm/v7")
+		}
+	}
+	return nil
+}
+
+// BinaryName is the name of the collector binary, defaulting to the
+// distribution ID.
+func (d Distribution) BinaryName() string {
+	if d.Release.Branding.BinaryName != "" {
+		return d.Release.Branding.BinaryName
+	}
+	return d.ID
+}
+
+// ServiceName is the name of the systemd service and of the directory
+// holding its configuration, defaulting to the binary name.
+func (d Distribution) ServiceName() string {
+	if d.Release.Branding.ServiceName != "" {
+		return d.Release.Branding.ServiceName
+	}
+	return d.BinaryName()
+}
+
+// VariantID is the ID of the builds, archives and images of a build variant.
+func (d Distribution) VariantID(variant BuildVariant) string {
+	return fmt.Sprintf("%s-%s", d.ID, variant.Name)
+}
+
+// VariantBinaryName is the name of the binary of a build variant.
+func (d Distribution) VariantBinaryName(variant BuildVariant) string {
+	return fmt.Sprintf("%s-%s", d.BinaryName(), variant.Name)
+}
+
+// VariantBuildDir is the directory holding the generated sources of a build
+// variant, relative to the distribution's directory.
+func VariantBuildDir(variant BuildVariant) string {
+	return fmt.Sprintf("_build-%s", variant.Name)
+}
+
+// SkipsLatest reports whether the images of the distribution leave out the
+// latest tags, as set by the distribution or for every distribution.
+func (d Distribution) SkipsLatest() bool {
+	return SkipLatest || d.Release.Docker.SkipLatest
+}
+
+// GoVersion is the pinned Go version, without the "go" prefix, or an empty
+// string when the distribution doesn't pin one.
+func (d Distribution) GoVersion() string {
+	return strings.TrimPrefix(d.Release.GoVersion, "go")
+}
+
+// Goos are the operating systems the distribution is built for, on top of
+// its extra targets.
+func (d Distribution) Goos() []string {
+	if len(d.Release.Goos) > 0 {
+		return d.Release.Goos
+	}
+	return OperatingSystems
+}
+
+// Goarch are the architectures the distribution is built for, on top of its
+// extra targets.
+func (d Distribution) Goarch() []string {
+	if len(d.Release.Goarch) > 0 {
+		return d.Release.Goarch
+	}
+	return Architectures
+}
+
+// PGOProfile is the path of the distribution's profile-guided optimization
+// profile, distributions/<dist>/default.pgo, relative to its sources, or an
+// empty string when it has none.
+func (d Distribution) PGOProfile() string {
+	if _, err := os.Stat(path.Join("distributions", d.ID, "default.pgo")); err != nil {
+		return ""
+	}
+	return path.Join("..", "default.pgo")
+}
+
+// pgoProfileFile is the path of the distribution's profile-guided
+// optimization profile relative to the repository, or an empty string when it
+// has none.
+func pgoProfileFile(d Distribution) string {
+	if d.PGOProfile() == "" {
+		return ""
+	}
+	return path.Join("distributions", d.ID, "default.pgo")
+}
+
+// BuildTags are the Go build tags the distribution is compiled with, from
+// ocb's build_tags, separated by commas or spaces.
+func (d Distribution) BuildTags() []string {
+	return strings.FieldsFunc(d.Dist.BuildTags, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
+
+// ArmVersions are the GOARM versions the distribution is built for.
+func (d Distribution) ArmVersions() []string {
+	if len(d.Release.ArmVersions) > 0 {
+		return d.Release.ArmVersions
+	}
+	return ArmVersions
+}
+
+// Ports are the ports exposed by the distribution's images, including the
+// health check's.
+func (d Distribution) Ports() []string {
+	ports := ExposedPorts
+	if len(d.Release.Docker.Ports) > 0 {
+		ports = d.Release.Docker.Ports
+	}
+	if d.Release.Docker.HealthCheck && !contains(ports, HealthCheckPort) {
+		ports = append(append([]string{}, ports...), HealthCheckPort)
+	}
+	return ports
+}
+
+// UID is the user the distribution's images run as.
+func (d Distribution) UID() int {
+	if d.Release.Docker.UID != 0 {
+		return d.Release.Docker.UID
+	}
+	return DefaultUID
+}
+
+// GID is the group the distribution's images run as.
+func (d Distribution) GID() int {
+	return d.Release.Docker.GID
+}
+
+// DisplayName is the human-readable name of the distribution.
+func (d Distribution) DisplayName() string {
+	if d.Release.Branding.DisplayName != "" {
+		return d.Release.Branding.DisplayName
+	}
+	return fmt.Sprintf("OpenTelemetry Collector - %s", d.ID)
+}
+
+// Description describes the distribution, defaulting to its display name.
+func (d Distribution) Description() string {
+	if d.Release.Metadata.Description != "" {
+		return d.Release.Metadata.Description
+	}
+	return d.DisplayName()
+}
+
+// Homepage is the distribution's homepage, defaulting to its support URL
+// and then to this repository.
+func (d Distribution) Homepage() string {
+	switch {
+	case d.Release.Metadata.Homepage != "":
+		return d.Release.Metadata.Homepage
+	case d.Release.Branding.SupportURL != "":
+		return d.Release.Branding.SupportURL
+	}
+	return "https://github.com/open-telemetry/opentelemetry-collector-releases"
+}
+
+// Maintainer is the maintainer of the distribution's packages.
+func (d Distribution) Maintainer() string {
+	if d.Release.Metadata.Maintainer != "" {
+		return d.Release.Metadata.Maintainer
+	}
+	return "The OpenTelemetry Collector maintainers <cncf-opentelemetry-maintainers@lists.cncf.io>"
+}
+
+// License is the SPDX license expression of the distribution.
+func (d Distribution) License() string {
+	if d.Release.Metadata.License != "" {
+		return d.Release.Metadata.License
+	}
+	return "Apache-2.0"
+}
+
+// Vendor is the organization distributing the collector.
+func (d Distribution) Vendor() string {
+	if d.Release.Metadata.Vendor != "" {
+		return d.Release.Metadata.Vendor
+	}
+	return "OpenTelemetry"
+}
+
+// Documentation is the URL of the distribution's documentation, defaulting
+// to the homepage of branded distributions and to the collector's
+// documentation otherwise.
+func (d Distribution) Documentation() string {
+	switch {
+	case d.Release.Metadata.Documentation != "":
+		return d.Release.Metadata.Documentation
+	case d.Release.Metadata.Homepage != "" || d.Release.Branding.SupportURL != "":
+		return d.Homepage()
+	}
+	return "https://opentelemetry.io/docs/collector/"
+}
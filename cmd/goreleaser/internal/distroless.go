@@ -0,0 +1,59 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"path"
+)
+
+// DistrolessDockerfile is the name of the Dockerfile of the distroless
+// images.
+const DistrolessDockerfile = "Dockerfile.distroless"
+
+// distrolessImageDockerfile is the path of the Dockerfile of the
+// distribution's distroless images.
+func distrolessImageDockerfile(dist Distribution) string {
+	return path.Join("distributions", dist.ID, DistrolessDockerfile)
+}
+
+// DistrolessImageDockerfile renders the Dockerfile of the distroless images
+// of a distribution from the shared template, without the OS packages of its
+// default image, copying the binary and the CA certificates onto an empty
+// image.
+func DistrolessImageDockerfile(dist Distribution) ([]byte, error) {
+	dist.Release.Docker.APKPackages = nil
+	dist.Release.Docker.APTPackages = nil
+	return renderDockerfile(dist, false)
+}
+
+// PublishesDistroless reports whether the distribution publishes distroless
+// images. It doesn't when its default image is already based on scratch, as
+// they would be identical.
+func (d Distribution) PublishesDistroless() bool {
+	return d.Release.Docker.Distroless && d.BaseImage() != "scratch"
+}
+
+// distrolessImageVariant is the distroless image of the distribution, tagged
+// such as 0.89.0-distroless.
+func distrolessImageVariant(dist Distribution) ImageVariant {
+	return ImageVariant{
+		Suffix:     "distroless",
+		BuildID:    dist.ID,
+		Files:      []string{path.Join("configs", fmt.Sprintf("%s.yaml", dist.ID))},
+		Dockerfile: distrolessImageDockerfile(dist),
+		BaseImage:  "scratch",
+	}
+}
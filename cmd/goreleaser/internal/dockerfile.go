@@ -0,0 +1,481 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const (
+	AlpineBaseImage = "alpine:3.16"
+	DebianBaseImage = "debian:bookworm-slim"
+
+	// HermeticGoVersion is the version of the golang image compiling the
+	// binary of hermetic images, unless the distribution pins a Go version.
+	HermeticGoVersion = "1.21.4"
+	// HermeticDockerfile is the name of the Dockerfile of hermetic images.
+	HermeticDockerfile = "Dockerfile.hermetic"
+)
+
+//go:embed templates/Dockerfile.tmpl
+var dockerfileTemplate string
+
+var dockerfileTmpl = template.Must(template.New("Dockerfile").
+	Funcs(template.FuncMap{"join": strings.Join}).
+	Parse(dockerfileTemplate))
+
+// GeneratesDockerfile reports whether the distribution's Dockerfile is
+// rendered by the generator instead of being written by hand.
+func (d Distribution) GeneratesDockerfile() bool {
+	return !d.Release.Docker.CustomDockerfile
+}
+
+// ImageDockerfile is the path of the Dockerfile building the distribution's
+// images.
+func (d Distribution) ImageDockerfile() string {
+	if d.Release.Docker.Hermetic {
+		return path.Join("distributions", d.ID, HermeticDockerfile)
+	}
+	return path.Join("distributions", d.ID, "Dockerfile")
+}
+
+// BaseImage is the base image of the distribution's images, read from its
+// Dockerfile unless the generator renders it or ko builds the images. It is
+// empty if the Dockerfile can't be read.
+func (d Distribution) BaseImage() string {
+	if d.Release.Docker.Ko {
+		return d.Release.Docker.KoBaseImage
+	}
+	if d.GeneratesDockerfile() || d.Release.Docker.Hermetic {
+		return renderedBaseImage(d.Release.Docker)
+	}
+	b, err := os.ReadFile(path.Join("distributions", d.ID, "Dockerfile"))
+	if err != nil {
+		return ""
+	}
+	return parseDockerfile(b).from
+}
+
+// renderedBaseImage is the base image of the rendered Dockerfiles: Debian
+// for apt packages, Alpine for apk packages, and scratch, with the CA
+// certificates only, without packages.
+func renderedBaseImage(docker DockerConfig) string {
+	switch {
+	case len(docker.APTPackages) > 0:
+		return DebianBaseImage
+	case len(docker.APKPackages) > 0:
+		return AlpineBaseImage
+	}
+	return "scratch"
+}
+
+// Dockerfile renders the Dockerfile of a distribution from the shared
+// template. The base image is picked based on the package manager in use.
+func Dockerfile(dist Distribution) ([]byte, error) {
+	return renderDockerfile(dist, false)
+}
+
+// HermeticImageDockerfile renders the Dockerfile of the hermetic images of a
+// distribution, which compiles the binary in a first stage.
+func HermeticImageDockerfile(dist Distribution) ([]byte, error) {
+	return renderDockerfile(dist, true)
+}
+
+func renderDockerfile(dist Distribution, hermetic bool) ([]byte, error) {
+	docker := dist.Release.Docker
+	if len(docker.APKPackages) > 0 && len(docker.APTPackages) > 0 {
+		return nil, fmt.Errorf("distribution %q declares both apk and apt packages", dist.ID)
+	}
+
+	goVersion := dist.GoVersion()
+	if goVersion == "" {
+		goVersion = HermeticGoVersion
+	}
+
+	var buf bytes.Buffer
+	err := dockerfileTmpl.Execute(&buf, struct {
+		ID          string
+		Binary      string
+		BaseImage   string
+		CertsImage  string
+		APKPackages []string
+		APTPackages []string
+		Ports       []string
+		Hermetic    bool
+		GoVersion   string
+		Sources     string
+		PGOProfile  string
+		Tags        []string
+		Env         []string
+		UID         int
+		GID         int
+		HealthCheck bool
+		HealthPort  string
+		Configs     []bundledConfig
+	}{
+		ID:          dist.ID,
+		Binary:      dist.BinaryName(),
+		BaseImage:   renderedBaseImage(docker),
+		CertsImage:  AlpineBaseImage,
+		APKPackages: docker.APKPackages,
+		APTPackages: docker.APTPackages,
+		Ports:       dist.Ports(),
+		Hermetic:    hermetic,
+		GoVersion:   goVersion,
+		Sources:     path.Join("distributions", dist.ID, "_build"),
+		PGOProfile:  pgoProfileFile(dist),
+		Tags:        dist.BuildTags(),
+		Env:         dockerEnv(dist.Release.Env),
+		UID:         dist.UID(),
+		GID:         dist.GID(),
+		HealthCheck: docker.HealthCheck,
+		HealthPort:  HealthCheckPort,
+		Configs:     bundledConfigs(dist),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the Dockerfile for distribution %q: %w", dist.ID, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteDockerfiles renders the Dockerfile of every distribution, leaving the
+// custom Dockerfiles untouched, and the Dockerfiles of the hermetic,
+// distroless, UBI and Windows images of the distributions opting in.
+func WriteDockerfiles(dists []Distribution) error {
+	_, err := forEach(dists, func(dist Distribution) (struct{}, error) {
+		if dist.GeneratesDockerfile() {
+			b, err := Dockerfile(dist)
+			if err != nil {
+				return struct{}{}, err
+			}
+			if err := os.WriteFile(path.Join("distributions", dist.ID, "Dockerfile"), b, 0o644); err != nil {
+				return struct{}{}, err
+			}
+		}
+		if dist.Release.Docker.Hermetic {
+			b, err := HermeticImageDockerfile(dist)
+			if err != nil {
+				return struct{}{}, err
+			}
+			if err := os.WriteFile(dist.ImageDockerfile(), b, 0o644); err != nil {
+				return struct{}{}, err
+			}
+		}
+		if dist.PublishesDistroless() {
+			b, err := DistrolessImageDockerfile(dist)
+			if err != nil {
+				return struct{}{}, err
+			}
+			if err := os.WriteFile(distrolessImageDockerfile(dist), b, 0o644); err != nil {
+				return struct{}{}, err
+			}
+		}
+		if dist.Release.Docker.UBI {
+			b, err := UBIImageDockerfile(dist)
+			if err != nil {
+				return struct{}{}, err
+			}
+			if err := os.WriteFile(ubiImageDockerfile(dist), b, 0o644); err != nil {
+				return struct{}{}, err
+			}
+		}
+		if len(dist.Release.Docker.Windows) > 0 {
+			b, err := WindowsImageDockerfile(dist)
+			if err != nil {
+				return struct{}{}, err
+			}
+			if err := os.WriteFile(windowsImageDockerfile(dist), b, 0o644); err != nil {
+				return struct{}{}, err
+			}
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// ValidateDockerfiles checks that the hand-written Dockerfile of each
+// distribution is consistent with the images goreleaser builds with it: for
+// every image variant, the binary copied is the one of the variant and is the
+// entrypoint, the config copied is the one passed to --config, every file
+// given to the build is copied and nothing else is, the image runs as a
+// non-root user, and the exposed ports are the distribution's.
+func ValidateDockerfiles(dists []Distribution) error {
+	var problems []string
+	for _, dist := range dists {
+		// The Windows Dockerfile is rendered, but the Windows images are
+		// built outside goreleaser, so nothing else checks it.
+		if len(dist.Release.Docker.Windows) > 0 {
+			b, err := WindowsImageDockerfile(dist)
+			if err != nil {
+				return err
+			}
+			df := parseDockerfile(b)
+			df.entrypoint, df.cmd = windowsPaths(df.entrypoint), windowsPaths(df.cmd)
+			for _, problem := range validateDockerfile(df, dist, windowsImageVariant(dist, dist.Release.Docker.Windows[0])) {
+				problems = append(problems, fmt.Sprintf("%s: %s", windowsImageDockerfile(dist), problem))
+			}
+		}
+		if dist.GeneratesDockerfile() {
+			continue
+		}
+		file := path.Join("distributions", dist.ID, "Dockerfile")
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		df := parseDockerfile(b)
+		expose := append([]string{}, df.expose...)
+		ports := append([]string{}, dist.Ports()...)
+		sort.Strings(expose)
+		sort.Strings(ports)
+		if strings.Join(expose, " ") != strings.Join(ports, " ") {
+			problems = append(problems, fmt.Sprintf("%s: exposes ports %s, expected %s", file, strings.Join(df.expose, " "), strings.Join(dist.Ports(), " ")))
+		}
+		for _, variant := range ImageVariants(dist) {
+			if variant.Dockerfile != "" {
+				continue
+			}
+			for _, problem := range validateDockerfile(df, dist, variant) {
+				problems = append(problems, fmt.Sprintf("%s: %s", file, problem))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("inconsistent Dockerfiles:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// dockerfile holds the instructions of the final stage of a Dockerfile
+// relevant to the consistency checks.
+type dockerfile struct {
+	// from is the base image of the final stage.
+	from string
+	// user is the user the final stage runs as, empty for root.
+	user       string
+	args       map[string]string
+	copies     [][]string
+	entrypoint []string
+	cmd        []string
+	expose     []string
+}
+
+// parseDockerfile reads the final stage of a Dockerfile, leaving out the
+// files copied from other stages.
+func parseDockerfile(b []byte) dockerfile {
+	df := dockerfile{args: map[string]string{}}
+	for _, line := range dockerfileLines(b) {
+		instruction, rest, _ := strings.Cut(line, " ")
+		rest = strings.TrimSpace(rest)
+		switch strings.ToUpper(instruction) {
+		case "FROM":
+			df.user, df.copies, df.entrypoint, df.cmd, df.expose = "", nil, nil, nil, nil
+			for _, field := range strings.Fields(rest) {
+				if !strings.HasPrefix(field, "--") {
+					df.from = field
+					break
+				}
+			}
+		case "ARG":
+			name, value, _ := strings.Cut(rest, "=")
+			df.args[name] = value
+		case "COPY", "ADD":
+			var operands []string
+			fromStage := false
+			for _, field := range strings.Fields(rest) {
+				if strings.HasPrefix(field, "--") {
+					fromStage = fromStage || strings.HasPrefix(field, "--from=")
+					continue
+				}
+				operands = append(operands, field)
+			}
+			if !fromStage && len(operands) >= 2 {
+				df.copies = append(df.copies, operands)
+			}
+		case "USER":
+			df.user = rest
+		case "ENTRYPOINT":
+			df.entrypoint = execForm(rest)
+		case "CMD":
+			df.cmd = execForm(rest)
+		case "EXPOSE":
+			for _, port := range strings.Fields(rest) {
+				df.expose = append(df.expose, strings.TrimSuffix(port, "/tcp"))
+			}
+		}
+	}
+	return df
+}
+
+// dockerfileLines returns the instructions of a Dockerfile, joining the
+// continued lines and leaving out the comments.
+func dockerfileLines(b []byte) []string {
+	var lines []string
+	var current strings.Builder
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasSuffix(line, "\\") {
+			current.WriteString(strings.TrimSuffix(line, "\\") + " ")
+			continue
+		}
+		current.WriteString(line)
+		if l := strings.TrimSpace(current.String()); l != "" {
+			lines = append(lines, l)
+		}
+		current.Reset()
+	}
+	return lines
+}
+
+// execForm returns the arguments of an ENTRYPOINT or CMD instruction, in
+// either the exec or the shell form.
+func execForm(s string) []string {
+	var args []string
+	if err := json.Unmarshal([]byte(s), &args); err == nil {
+		return args
+	}
+	return strings.Fields(s)
+}
+
+func validateDockerfile(df dockerfile, dist Distribution, variant ImageVariant) []string {
+	args := map[string]string{}
+	for name, value := range df.args {
+		args[name] = value
+	}
+	// The build arguments override each other in the order of the image's
+	// build flags.
+	buildArgs := userBuildArgs(dist)
+	for _, arg := range dist.Release.Docker.BuildArgs {
+		buildArgs = append(buildArgs, "--build-arg="+arg)
+	}
+	for _, arg := range append(buildArgs, variant.BuildArgs...) {
+		if name, value, ok := strings.Cut(strings.TrimPrefix(arg, "--build-arg="), "="); ok {
+			args[name] = value
+		}
+	}
+	expand := func(s string) string {
+		return os.Expand(s, func(name string) string { return args[name] })
+	}
+
+	image := imageName(dist) + ":" + variant.tag("<version>")
+	binary := imageBinaryName(dist, variant)
+	var config string
+	if len(variant.Files) > 0 {
+		config = variant.Files[0]
+	}
+
+	// destinations maps the files of the build context copied into the image
+	// to their destination.
+	destinations := map[string]string{}
+	for _, operands := range df.copies {
+		dest := expand(operands[len(operands)-1])
+		for _, src := range operands[:len(operands)-1] {
+			src = expand(src)
+			if strings.HasSuffix(dest, "/") {
+				destinations[src] = dest + path.Base(src)
+			} else {
+				destinations[src] = dest
+			}
+		}
+	}
+
+	var problems []string
+	if user := expand(df.user); isRoot(user) {
+		problems = append(problems, fmt.Sprintf("image %s runs as root, its final stage must set a non-root USER", image))
+	}
+	binaryDest, ok := destinations[binary]
+	switch {
+	case !ok:
+		problems = append(problems, fmt.Sprintf("image %s doesn't copy the %s binary", image, binary))
+	case len(df.entrypoint) == 0 || df.entrypoint[0] != binaryDest:
+		problems = append(problems, fmt.Sprintf("image %s copies the %s binary to %s, which isn't the entrypoint", image, binary, binaryDest))
+	}
+	if configDest, ok := destinations[config]; ok {
+		command := append(append([]string{}, df.entrypoint...), df.cmd...)
+		if !contains(command, configDest) && !contains(command, "--config="+configDest) {
+			problems = append(problems, fmt.Sprintf("image %s copies the %s config to %s, which isn't passed to --config", image, config, configDest))
+		}
+	}
+	for _, file := range variant.Files {
+		if _, ok := destinations[file]; !ok {
+			problems = append(problems, fmt.Sprintf("image %s doesn't copy %s", image, file))
+		}
+	}
+	var copied []string
+	for src := range destinations {
+		copied = append(copied, src)
+	}
+	sort.Strings(copied)
+	for _, src := range copied {
+		if src != binary && !contains(variant.Files, src) {
+			problems = append(problems, fmt.Sprintf("image %s copies %s, which isn't part of its build context", image, src))
+		}
+	}
+	return problems
+}
+
+// imageBinaryName is the name of the binary goreleaser puts in the build
+// context of the image variant.
+func imageBinaryName(dist Distribution, variant ImageVariant) string {
+	if strings.HasPrefix(variant.Suffix, "windows-") {
+		return dist.BinaryName() + ".exe"
+	}
+	if variant.BuildID == dist.FIPSID() {
+		return dist.FIPSBinaryName()
+	}
+	for _, bv := range dist.Release.BuildVariants {
+		if dist.VariantID(bv) == variant.BuildID {
+			return dist.VariantBinaryName(bv)
+		}
+	}
+	return dist.BinaryName()
+}
+
+// dockerEnv formats the distribution's env as the arguments of ENV
+// instructions, with the values quoted.
+func dockerEnv(env []string) (r []string) {
+	for _, e := range env {
+		key, value, _ := strings.Cut(e, "=")
+		r = append(r, fmt.Sprintf("%s=%q", key, value))
+	}
+	return
+}
+
+// userBuildArgs are the build arguments setting the user and group the
+// images of the distribution run as.
+func userBuildArgs(dist Distribution) []string {
+	return []string{
+		fmt.Sprintf("--build-arg=USER_UID=%d", dist.UID()),
+		fmt.Sprintf("--build-arg=USER_GID=%d", dist.GID()),
+	}
+}
+
+// isRoot tells whether the user of a USER instruction, as user[:group],
+// is root, or the administrator of the Windows images.
+func isRoot(user string) bool {
+	name, _, _ := strings.Cut(user, ":")
+	return name == "" || name == "0" || name == "root" || name == "ContainerAdministrator"
+}
@@ -0,0 +1,228 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// qemuNames are the names of the qemu binfmt handlers emulating the image
+// architectures. The x86 ones run natively on the release runners.
+var qemuNames = map[string]string{
+	"arm":     "arm",
+	"arm64":   "aarch64",
+	"ppc64le": "ppc64le",
+	"s390x":   "s390x",
+}
+
+// DoctorCheck is a requirement of the release pipeline on the local
+// environment.
+type DoctorCheck struct {
+	Name string
+	Run  func() error
+	// Fix tells how to satisfy the requirement.
+	Fix string
+}
+
+// DoctorChecks lists the requirements of the release pipeline publishing
+// images under the given prefixes.
+func DoctorChecks(imagePrefixes []string) []DoctorCheck {
+	checks := []DoctorCheck{
+		{
+			Name: "docker",
+			Run:  func() error { return run("docker", "version") },
+			Fix:  "install Docker and make sure its daemon is running: https://docs.docker.com/engine/install/",
+		},
+		{
+			Name: "docker buildx",
+			Run:  func() error { return run("docker", "buildx", "version") },
+			Fix:  "install the buildx plugin: https://github.com/docker/buildx#installing",
+		},
+	}
+	for _, arch := range Architectures {
+		name, ok := qemuNames[arch]
+		if !ok {
+			continue
+		}
+		checks = append(checks, DoctorCheck{
+			Name: fmt.Sprintf("qemu emulation for %s", arch),
+			Run: func() error {
+				_, err := os.Stat(filepath.Join("/proc/sys/fs/binfmt_misc", "qemu-"+name))
+				return err
+			},
+			Fix: "docker run --rm --privileged multiarch/qemu-user-static --reset -p yes",
+		})
+	}
+	checks = append(checks,
+		DoctorCheck{
+			Name: "goreleaser-pro",
+			Run:  checkGoreleaserPro,
+			Fix:  "install goreleaser-pro, which the generated configuration requires: https://goreleaser.com/install/",
+		},
+		DoctorCheck{
+			Name: "syft",
+			Run:  func() error { return run("syft", "version") },
+			Fix:  "install syft, generating the SBOMs: https://github.com/anchore/syft#installation",
+		},
+		DoctorCheck{
+			Name: "trivy or grype",
+			Run: func() error {
+				if err := run("trivy", "--version"); err == nil {
+					return nil
+				}
+				return run("grype", "version")
+			},
+			Fix: "install trivy or grype, scanning the images before they are published: https://github.com/anchore/grype#installation",
+		},
+		DoctorCheck{
+			Name: "cosign",
+			Run:  func() error { return run("cosign", "version") },
+			Fix:  "install cosign, signing the artifacts: https://docs.sigstore.dev/system_config/installation/",
+		},
+		DoctorCheck{
+			Name: "GORELEASER_KEY",
+			Run:  func() error { return checkEnv("GORELEASER_KEY") },
+			Fix:  "export GORELEASER_KEY with the goreleaser-pro license key",
+		},
+		DoctorCheck{
+			Name: "GITHUB_TOKEN",
+			Run:  func() error { return checkEnv("GITHUB_TOKEN") },
+			Fix:  "export GITHUB_TOKEN with a token allowed to create releases",
+		},
+	)
+	for _, registry := range registries(imagePrefixes) {
+		registry := registry
+		checks = append(checks, DoctorCheck{
+			Name: fmt.Sprintf("credentials for %s", registry),
+			Run:  func() error { return checkDockerCredentials(registry) },
+			Fix:  fmt.Sprintf("docker login %s", registry),
+		})
+	}
+	return checks
+}
+
+// RunDoctor runs the checks, printing their result along with how to fix the
+// failing ones, and reports whether all of them passed.
+func RunDoctor(w io.Writer, checks []DoctorCheck) bool {
+	ok := true
+	for _, check := range checks {
+		if err := check.Run(); err != nil {
+			ok = false
+			fmt.Fprintf(w, "❌ %s: %v\n   fix: %s\n", check.Name, err, check.Fix)
+			continue
+		}
+		fmt.Fprintf(w, "✅ %s\n", check.Name)
+	}
+	return ok
+}
+
+func run(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if errors.Is(err, exec.ErrNotFound) {
+		return fmt.Errorf("%s not found", name)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func checkGoreleaserPro() error {
+	out, err := exec.Command("goreleaser", "--version").CombinedOutput()
+	if errors.Is(err, exec.ErrNotFound) {
+		return errors.New("goreleaser not found")
+	}
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(string(out), "pro") {
+		return errors.New("goreleaser is not the pro distribution")
+	}
+	return nil
+}
+
+func checkEnv(name string) error {
+	if os.Getenv(name) == "" {
+		return fmt.Errorf("%s is not set", name)
+	}
+	return nil
+}
+
+// registries returns the registries hosting the given image prefixes, Docker
+// Hub being the default one.
+func registries(imagePrefixes []string) []string {
+	var r []string
+	for _, prefix := range imagePrefixes {
+		registry := "docker.io"
+		if first, _, ok := strings.Cut(prefix, "/"); ok && strings.ContainsAny(first, ".:") {
+			registry = first
+		}
+		if !contains(r, registry) {
+			r = append(r, registry)
+		}
+	}
+	return r
+}
+
+// checkDockerCredentials checks that the Docker configuration holds
+// credentials for the registry, or delegates them to a credentials store.
+func checkDockerCredentials(registry string) error {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return fmt.Errorf("failed to read the Docker configuration: %w", err)
+	}
+	var cfg struct {
+		Auths       map[string]json.RawMessage `json:"auths"`
+		CredsStore  string                     `json:"credsStore"`
+		CredHelpers map[string]string          `json:"credHelpers"`
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return fmt.Errorf("failed to parse the Docker configuration: %w", err)
+	}
+
+	keys := []string{registry}
+	if registry == "docker.io" {
+		keys = append(keys, "https://index.docker.io/v1/", "index.docker.io")
+	}
+	for _, key := range keys {
+		if _, ok := cfg.Auths[key]; ok {
+			return nil
+		}
+		if _, ok := cfg.CredHelpers[key]; ok {
+			return nil
+		}
+	}
+	if cfg.CredsStore != "" {
+		// The credentials store may hold them, which can't be checked
+		// without reading the secrets.
+		return nil
+	}
+	return fmt.Errorf("not logged in to %s", registry)
+}
@@ -0,0 +1,52 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// RestrictTargets limits the builds and images of a project to the given
+// goreleaser targets, such as "linux_amd64_v1" or "linux_arm_7".
+// https://goreleaser.com/customization/build/#targets
+func RestrictTargets(project *Project, targets []string) {
+	for i := range project.Builds {
+		project.Builds[i].Targets = targets
+	}
+
+	var dockers []config.Docker
+	for _, docker := range project.Dockers {
+		if matchesTargets(docker, targets) {
+			dockers = append(dockers, docker)
+		}
+	}
+	project.Dockers = dockers
+}
+
+func matchesTargets(docker config.Docker, targets []string) bool {
+	target := fmt.Sprintf("%s_%s", docker.Goos, docker.Goarch)
+	if docker.Goarm != "" {
+		target = fmt.Sprintf("%s_%s", target, docker.Goarm)
+	}
+	for _, t := range targets {
+		if t == target || strings.HasPrefix(t, target+"_") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path"
+)
+
+//go:embed templates/embedded_config.go.tmpl
+var embeddedConfigSource []byte
+
+// WriteEmbeddedConfigs adds the default configuration to the generated
+// sources of every distribution opting in, along with the code falling back
+// to it when the collector is started without a configuration. It must run
+// after ocb generated the sources and before they are compiled.
+func WriteEmbeddedConfigs(dists []Distribution) error {
+	_, err := forEach(dists, func(dist Distribution) (struct{}, error) {
+		if !dist.Release.EmbedConfig {
+			return struct{}{}, nil
+		}
+		if err := writeEmbeddedConfig(dist); err != nil {
+			return struct{}{}, fmt.Errorf("failed to embed the config of distribution %q: %w", dist.ID, err)
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+func writeEmbeddedConfig(dist Distribution) error {
+	buildDir := path.Join("distributions", dist.ID, "_build")
+	cfg, err := os.ReadFile(path.Join("configs", fmt.Sprintf("%s.yaml", dist.ID)))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path.Join(buildDir, "embedded_config.yaml"), cfg, 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(path.Join(buildDir, "embedded_config.go"), embeddedConfigSource, 0o644)
+}
@@ -0,0 +1,297 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UpdateFeedDir is the directory the update feed is written to, as
+// <channel>/<distribution>/manifest.yaml.
+const UpdateFeedDir = "_update-feed"
+
+// Release channels of the update feed.
+const (
+	StableChannel  = "stable"
+	RCChannel      = "rc"
+	NightlyChannel = "nightly"
+)
+
+// channels are the release channels, from the most to the least stable.
+var channels = []string{StableChannel, RCChannel, NightlyChannel}
+
+// UpdateManifest lists the releases of a distribution in a channel, in the
+// format of the HTTP source of go-selfupdate,
+// https://github.com/creativeprojects/go-selfupdate, whose clients read it
+// from <base URL>/<channel>/<distribution>/manifest.yaml, with the
+// repository slug <channel>/<distribution>.
+type UpdateManifest struct {
+	LastReleaseID int64            `yaml:"last_release_id"`
+	LastAssetID   int64            `yaml:"last_asset_id"`
+	Releases      []*UpdateRelease `yaml:"releases"`
+}
+
+// UpdateRelease is a release of a distribution, with its archives, the
+// checksums file go-selfupdate's checksum validator verifies them with, and
+// the cosign signature and certificate of the checksums file.
+type UpdateRelease struct {
+	ID          int64          `yaml:"id"`
+	Name        string         `yaml:"name"`
+	TagName     string         `yaml:"tag_name"`
+	URL         string         `yaml:"url"`
+	Prerelease  bool           `yaml:"prerelease"`
+	PublishedAt time.Time      `yaml:"published_at"`
+	Assets      []*UpdateAsset `yaml:"assets"`
+}
+
+// UpdateAsset is a release asset, downloaded from the GitHub release.
+type UpdateAsset struct {
+	ID   int64  `yaml:"id"`
+	Name string `yaml:"name"`
+	Size int    `yaml:"size"`
+	URL  string `yaml:"url"`
+}
+
+// UpdateFeed is the release published by goreleaser, as added to the update
+// feed.
+type UpdateFeed struct {
+	Channel string
+	Version string
+	// Releases are the release of each distribution, by distribution.
+	Releases map[string]*UpdateRelease
+}
+
+// releaseMetadata is the part of goreleaser's dist/metadata.json describing
+// the release.
+type releaseMetadata struct {
+	ProjectName string `json:"project_name"`
+	Tag         string `json:"tag"`
+	Version     string `json:"version"`
+	Date        string `json:"date"`
+}
+
+// releaseArtifact is an entry of goreleaser's dist/artifacts.json.
+type releaseArtifact struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Goos    string `json:"goos"`
+	Goarch  string `json:"goarch"`
+	Goarm   string `json:"goarm"`
+	Goamd64 string `json:"goamd64"`
+	Gomips  string `json:"gomips"`
+	Type    string `json:"type"`
+	Extra   struct {
+		ID       string `json:"ID"`
+		Checksum string `json:"Checksum"`
+		Digest   string `json:"Digest"`
+	} `json:"extra"`
+}
+
+// ReleaseChannel is the channel of a version: nightly for nightly and
+// snapshot builds, rc for any other prerelease, and stable otherwise.
+func ReleaseChannel(version string) string {
+	switch {
+	case strings.Contains(version, "nightly") || strings.Contains(version, "SNAPSHOT"):
+		return NightlyChannel
+	case strings.Contains(version, "-"):
+		return RCChannel
+	}
+	return StableChannel
+}
+
+// BuildUpdateFeed reads the release published by goreleaser from distDir and
+// describes the archives of the given distributions, along with the signed
+// checksums file, as downloaded from the GitHub releases of repo.
+func BuildUpdateFeed(distDir, repo string, dists []Distribution) (UpdateFeed, error) {
+	var metadata releaseMetadata
+	if err := readJSON(filepath.Join(distDir, "metadata.json"), &metadata); err != nil {
+		return UpdateFeed{}, err
+	}
+	var artifacts []releaseArtifact
+	if err := readJSON(filepath.Join(distDir, "artifacts.json"), &artifacts); err != nil {
+		return UpdateFeed{}, err
+	}
+	published, err := time.Parse(time.RFC3339, metadata.Date)
+	if err != nil {
+		return UpdateFeed{}, fmt.Errorf("failed to parse the date of the release: %w", err)
+	}
+
+	asset := func(a releaseArtifact) (*UpdateAsset, error) {
+		info, err := os.Stat(a.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the size of %s: %w", a.Name, err)
+		}
+		return &UpdateAsset{
+			Name: a.Name,
+			Size: int(info.Size()),
+			URL:  fmt.Sprintf("https://github.com/%s/releases/download/%s/%s", repo, metadata.Tag, a.Name),
+		}, nil
+	}
+	var checksums *UpdateAsset
+	for _, a := range artifacts {
+		if a.Type == "Checksum" {
+			if checksums, err = asset(a); err != nil {
+				return UpdateFeed{}, err
+			}
+		}
+	}
+	if checksums == nil {
+		return UpdateFeed{}, fmt.Errorf("no checksums file found in %s", distDir)
+	}
+	// The checksums file is signed with cosign, its signature and
+	// certificate named after it.
+	signed := []*UpdateAsset{checksums}
+	for _, s := range []struct{ typ, name string }{
+		{"Signature", checksums.Name + ".sig"},
+		{"Certificate", checksums.Name + ".pem"},
+	} {
+		var found *UpdateAsset
+		for _, a := range artifacts {
+			if a.Type == s.typ && a.Name == s.name {
+				if found, err = asset(a); err != nil {
+					return UpdateFeed{}, err
+				}
+			}
+		}
+		if found == nil {
+			return UpdateFeed{}, fmt.Errorf("no %s of the checksums file found in %s", strings.ToLower(s.typ), distDir)
+		}
+		signed = append(signed, found)
+	}
+
+	feed := UpdateFeed{
+		Channel:  ReleaseChannel(metadata.Version),
+		Version:  metadata.Version,
+		Releases: map[string]*UpdateRelease{},
+	}
+	for _, dist := range dists {
+		release := &UpdateRelease{
+			Name:        fmt.Sprintf("%s %s", dist.DisplayName(), metadata.Version),
+			TagName:     metadata.Tag,
+			URL:         fmt.Sprintf("https://github.com/%s/releases/tag/%s", repo, metadata.Tag),
+			Prerelease:  feed.Channel != StableChannel,
+			PublishedAt: published.UTC(),
+		}
+		for _, a := range artifacts {
+			if a.Type != "Archive" || a.Extra.ID != dist.ID {
+				continue
+			}
+			archive, err := asset(a)
+			if err != nil {
+				return UpdateFeed{}, err
+			}
+			release.Assets = append(release.Assets, archive)
+		}
+		if len(release.Assets) == 0 {
+			return UpdateFeed{}, fmt.Errorf("no archive of distribution %q found in %s", dist.ID, distDir)
+		}
+		for _, a := range signed {
+			a := *a
+			release.Assets = append(release.Assets, &a)
+		}
+		feed.Releases[dist.ID] = release
+	}
+	return feed, nil
+}
+
+// WriteUpdateFeed adds the releases of the feed to the manifests of their
+// distributions in dir, for the channel of the feed and the less stable
+// ones, replacing a release with the same tag, and returns the paths of the
+// manifests.
+func WriteUpdateFeed(dir string, feed UpdateFeed) ([]string, error) {
+	ids := make([]string, 0, len(feed.Releases))
+	for id := range feed.Releases {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var paths []string
+	for _, channel := range channels[indexOf(channels, feed.Channel):] {
+		for _, id := range ids {
+			p := filepath.Join(dir, channel, id, "manifest.yaml")
+			var manifest UpdateManifest
+			b, err := os.ReadFile(p)
+			switch {
+			case errors.Is(err, fs.ErrNotExist):
+			case err != nil:
+				return nil, err
+			default:
+				if err := yaml.Unmarshal(b, &manifest); err != nil {
+					return nil, fmt.Errorf("failed to parse %s: %w", p, err)
+				}
+			}
+			addUpdateRelease(&manifest, *feed.Releases[id])
+			if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+				return nil, err
+			}
+			if err := writeYAML(p, "", manifest); err != nil {
+				return nil, err
+			}
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+// addUpdateRelease adds the release to the manifest, replacing the one with
+// the same tag, and gives it and its assets new IDs.
+func addUpdateRelease(manifest *UpdateManifest, release UpdateRelease) {
+	releases := manifest.Releases[:0]
+	for _, r := range manifest.Releases {
+		if r.TagName != release.TagName {
+			releases = append(releases, r)
+		}
+	}
+	manifest.LastReleaseID++
+	release.ID = manifest.LastReleaseID
+	assets := make([]*UpdateAsset, 0, len(release.Assets))
+	for _, a := range release.Assets {
+		a := *a
+		manifest.LastAssetID++
+		a.ID = manifest.LastAssetID
+		assets = append(assets, &a)
+	}
+	release.Assets = assets
+	manifest.Releases = append(releases, &release)
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+func readJSON(file string, v interface{}) error {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// writeRelease writes the metadata.json and artifacts.json of a release of
+// otelcol to a goreleaser dist directory, along with its assets.
+func writeRelease(t *testing.T, version string) string {
+	t.Helper()
+	dir := t.TempDir()
+	metadata := releaseMetadata{
+		ProjectName: "opentelemetry-collector-releases",
+		Tag:         "v" + version,
+		Version:     version,
+		Date:        "2023-10-15T03:00:00Z",
+	}
+	var artifacts []map[string]interface{}
+	for _, a := range []struct{ name, typ, id string }{
+		{"otelcol_" + version + "_linux_amd64.tar.gz", "Archive", "otelcol"},
+		{"otelcol-contrib_" + version + "_linux_amd64.tar.gz", "Archive", "otelcol-contrib"},
+		{"opentelemetry-collector-releases_checksums.txt", "Checksum", ""},
+		{"opentelemetry-collector-releases_checksums.txt.sig", "Signature", ""},
+		{"opentelemetry-collector-releases_checksums.txt.pem", "Certificate", ""},
+	} {
+		p := filepath.Join(dir, a.name)
+		if err := os.WriteFile(p, []byte(a.name), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		artifacts = append(artifacts, map[string]interface{}{
+			"name":  a.name,
+			"path":  p,
+			"type":  a.typ,
+			"extra": map[string]string{"ID": a.id},
+		})
+	}
+	for file, v := range map[string]interface{}{"metadata.json": metadata, "artifacts.json": artifacts} {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, file), b, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestUpdateFeed(t *testing.T) {
+	dists := []Distribution{{ID: "otelcol"}}
+	feedDir := t.TempDir()
+	for _, tc := range []struct {
+		version  string
+		channels []string
+	}{
+		{"0.89.0", []string{StableChannel, RCChannel, NightlyChannel}},
+		{"0.90.0-nightly.20231015", []string{NightlyChannel}},
+	} {
+		t.Run(tc.version, func(t *testing.T) {
+			feed, err := BuildUpdateFeed(writeRelease(t, tc.version), "open-telemetry/opentelemetry-collector-releases", dists)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if feed.Channel != tc.channels[0] {
+				t.Errorf("channel = %q, want %q", feed.Channel, tc.channels[0])
+			}
+			paths, err := WriteUpdateFeed(feedDir, feed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want []string
+			for _, channel := range tc.channels {
+				want = append(want, filepath.Join(feedDir, channel, "otelcol", "manifest.yaml"))
+			}
+			if !reflect.DeepEqual(paths, want) {
+				t.Fatalf("manifests = %q, want %q", paths, want)
+			}
+
+			wantAssets := []string{
+				"otelcol_" + tc.version + "_linux_amd64.tar.gz",
+				"opentelemetry-collector-releases_checksums.txt",
+				"opentelemetry-collector-releases_checksums.txt.sig",
+				"opentelemetry-collector-releases_checksums.txt.pem",
+			}
+			for _, p := range paths {
+				b, err := os.ReadFile(p)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var manifest UpdateManifest
+				if err := yaml.Unmarshal(b, &manifest); err != nil {
+					t.Fatal(err)
+				}
+				release := manifest.Releases[len(manifest.Releases)-1]
+				if release.TagName != "v"+tc.version {
+					t.Fatalf("%s: latest release = %q, want %q", p, release.TagName, "v"+tc.version)
+				}
+				var assets []string
+				for _, a := range release.Assets {
+					assets = append(assets, a.Name)
+					if want := "https://github.com/open-telemetry/opentelemetry-collector-releases/releases/download/v" + tc.version + "/" + a.Name; a.URL != want {
+						t.Errorf("%s: URL of %s = %q, want %q", p, a.Name, a.URL, want)
+					}
+					if a.Size != len(a.Name) {
+						t.Errorf("%s: size of %s = %d, want %d", p, a.Name, a.Size, len(a.Name))
+					}
+				}
+				if !reflect.DeepEqual(assets, wantAssets) {
+					t.Errorf("%s: assets = %q, want %q", p, assets, wantAssets)
+				}
+			}
+		})
+	}
+}
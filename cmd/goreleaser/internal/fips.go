@@ -0,0 +1,78 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// FIPSArchitectures are the linux architectures the FIPS builds are made for,
+// the only ones Go supports BoringCrypto on.
+var FIPSArchitectures = []string{"amd64", "arm64"}
+
+// FIPSID is the ID of the build, archives and images of the distribution's
+// FIPS build.
+func (d Distribution) FIPSID() string {
+	return d.ID + "-fips"
+}
+
+// FIPSBinaryName is the name of the binary of the FIPS build.
+func (d Distribution) FIPSBinaryName() string {
+	return d.BinaryName() + "-fips"
+}
+
+// fipsArchitectures are the architectures of the distribution's FIPS build.
+func fipsArchitectures(dist Distribution) (r []string) {
+	for _, arch := range FIPSArchitectures {
+		if contains(dist.Goarch(), arch) && !contains(dist.Release.ExcludeTargets, "linux/"+arch) {
+			r = append(r, arch)
+		}
+	}
+	return
+}
+
+// FIPSBuild configures the FIPS build of a distribution, compiled for linux
+// with Go's BoringCrypto module, which requires cgo. The binary is linked
+// statically, so that it runs on the same base images as the others.
+// https://go.dev/src/crypto/internal/boring/README
+func FIPSBuild(dist Distribution) config.Build {
+	b := Build(dist)
+	b.ID = dist.FIPSID()
+	b.Binary = dist.FIPSBinaryName()
+	b.Goos = []string{"linux"}
+	b.Goarch = fipsArchitectures(dist)
+	b.Goarm = nil
+	b.Gomips = nil
+	b.Ignore = nil
+	// The last value of a variable wins, so GOEXPERIMENT can't be
+	// overridden by the distribution's env.
+	b.Env = append(buildEnv(dist, true), "GOEXPERIMENT=boringcrypto")
+	b.Tags = append(b.Tags, "netgo", "osusergo")
+	b.Ldflags = append(b.Ldflags, "-linkmode=external", "-extldflags=-static")
+	b.BuildDetailsOverrides = targetOverrides(dist, b, CgoCompilers)
+	return b
+}
+
+// FIPSArchive configures the archives of the FIPS build, named after its
+// binary.
+func FIPSArchive(dist Distribution) config.Archive {
+	return config.Archive{
+		ID:           dist.FIPSID(),
+		NameTemplate: archiveNameTemplate,
+		Builds:       []string{dist.FIPSID()},
+		BuildsInfo:   config.FileInfo{MTime: commitDate},
+		Files:        archiveFiles(),
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// HealthCheckModule is the module of the health_check extension, which
+	// the distributions wiring health checks into their images must include.
+	HealthCheckModule = "github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckextension"
+
+	// HealthCheckPort is the default port of the health_check extension,
+	// exposed by the images of the distributions wiring health checks and
+	// probed by their HEALTHCHECK.
+	HealthCheckPort = "13133"
+)
+
+// includesHealthCheck reports whether the distribution includes the
+// health_check extension.
+func includesHealthCheck(dist Distribution) bool {
+	for _, ext := range dist.Extensions {
+		if fields := strings.Fields(ext.GoMod); len(fields) > 0 && fields[0] == HealthCheckModule {
+			return true
+		}
+	}
+	return false
+}
+
+// imageConfigs are the configs copied into the distribution's images: the
+// default one, those of the config variants and the bundled ones.
+func imageConfigs(dist Distribution) []string {
+	configs := []string{path.Join("configs", fmt.Sprintf("%s.yaml", dist.ID))}
+	for _, variant := range dist.Release.ConfigVariants {
+		configs = append(configs, configVariantPath(dist, variant))
+	}
+	return withBundledConfigs(dist, configs)
+}
+
+// collectorConfig is the part of a collector config the health check
+// consistency checks read.
+type collectorConfig struct {
+	Extensions map[string]struct {
+		Endpoint string `yaml:"endpoint"`
+	} `yaml:"extensions"`
+	Service struct {
+		Extensions []string `yaml:"extensions"`
+	} `yaml:"service"`
+}
+
+// CheckHealthChecks fails unless every config copied into the images of the
+// distributions wiring health checks enables the health_check extension on
+// HealthCheckPort, which their images expose and probe.
+func CheckHealthChecks(dists []Distribution) error {
+	var problems []string
+	for _, dist := range dists {
+		if !dist.Release.Docker.HealthCheck {
+			continue
+		}
+		for _, file := range imageConfigs(dist) {
+			b, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			var cfg collectorConfig
+			if err := yaml.Unmarshal(b, &cfg); err != nil {
+				return fmt.Errorf("failed to parse %s: %w", file, err)
+			}
+			if problem := checkHealthCheck(cfg); problem != "" {
+				problems = append(problems, fmt.Sprintf("%s: %s", file, problem))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("inconsistent health checks:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// checkHealthCheck returns the problem of a config with the health check of
+// the images, if any.
+func checkHealthCheck(cfg collectorConfig) string {
+	for _, id := range cfg.Service.Extensions {
+		if id != "health_check" && !strings.HasPrefix(id, "health_check/") {
+			continue
+		}
+		// The extension listens on all interfaces by default.
+		endpoint := cfg.Extensions[id].Endpoint
+		if endpoint != "" && !strings.HasSuffix(endpoint, ":"+HealthCheckPort) {
+			return fmt.Sprintf("the %s extension listens on %s instead of port %s", id, endpoint, HealthCheckPort)
+		}
+		return ""
+	}
+	return "the health_check extension isn't enabled"
+}
@@ -0,0 +1,195 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// InventoryDir holds the component inventories attached to the releases.
+const InventoryDir = "_inventory"
+
+// Component is a component included in a distribution.
+type Component struct {
+	// Kind is the manifest section listing the component, such as
+	// "receivers".
+	Kind string `json:"kind"`
+	// Type is the component's type, as used in the collector configuration.
+	Type    string `json:"type"`
+	Module  string `json:"module"`
+	Version string `json:"version"`
+	// Stability maps stability levels to the signals at that level, as
+	// declared by the component's metadata.yaml.
+	Stability map[string][]string `json:"stability,omitempty"`
+}
+
+// componentMetadata is the part of a component's metadata.yaml describing
+// it.
+type componentMetadata struct {
+	Type   string `yaml:"type"`
+	Status struct {
+		Stability map[string][]string `yaml:"stability"`
+	} `yaml:"status"`
+}
+
+// Inventory lists the components of a distribution. Their stability is read
+// from the metadata.yaml of their module, downloaded through the Go module
+// proxy.
+func Inventory(dist Distribution) ([]Component, error) {
+	components, err := manifestComponents(dist)
+	if err != nil {
+		return nil, err
+	}
+	for i, component := range components {
+		metadata, err := readComponentMetadata(component.Module, component.Version)
+		if err != nil {
+			return nil, err
+		}
+		if metadata.Type != "" {
+			components[i].Type = metadata.Type
+		}
+		components[i].Stability = metadata.Status.Stability
+	}
+	return components, nil
+}
+
+// manifestComponents lists the components declared by a distribution's
+// manifest.
+func manifestComponents(dist Distribution) ([]Component, error) {
+	manifest := path.Join("distributions", dist.ID, "manifest.yaml")
+	sections := map[string][]Module{
+		"receivers":  dist.Receivers,
+		"exporters":  dist.Exporters,
+		"extensions": dist.Extensions,
+		"processors": dist.Processors,
+		"connectors": dist.Connectors,
+	}
+	var r []Component
+	for _, kind := range componentSections {
+		for _, c := range sections[kind] {
+			fields := strings.Fields(c.GoMod)
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("invalid gomod %q in %s", c.GoMod, manifest)
+			}
+			r = append(r, Component{
+				Kind:    kind,
+				Type:    strings.TrimSuffix(path.Base(fields[0]), strings.TrimSuffix(kind, "s")),
+				Module:  fields[0],
+				Version: fields[1],
+			})
+		}
+	}
+	return r, nil
+}
+
+func readComponentMetadata(module, version string) (componentMetadata, error) {
+	var metadata componentMetadata
+	// go mod download reports its errors in its JSON output.
+	out, _ := exec.Command("go", "mod", "download", "-json", fmt.Sprintf("%s@%s", module, version)).Output()
+	var download struct {
+		Dir   string
+		Error string
+	}
+	if err := json.Unmarshal(out, &download); err != nil {
+		return metadata, fmt.Errorf("failed to download %s@%s: %w", module, version, err)
+	}
+	if download.Error != "" {
+		return metadata, fmt.Errorf("failed to download %s@%s: %s", module, version, download.Error)
+	}
+
+	b, err := os.ReadFile(path.Join(download.Dir, "metadata.yaml"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return metadata, nil
+	}
+	if err != nil {
+		return metadata, err
+	}
+	if err := yaml.Unmarshal(b, &metadata); err != nil {
+		return metadata, fmt.Errorf("failed to parse the metadata of %s@%s: %w", module, version, err)
+	}
+	return metadata, nil
+}
+
+// WriteInventories writes the component inventory of each distribution to
+// InventoryDir, as <dist>_components.md and <dist>_components.json.
+func WriteInventories(dists []Distribution) error {
+	if err := os.MkdirAll(InventoryDir, 0o755); err != nil {
+		return err
+	}
+	_, err := forEach(dists, func(dist Distribution) (struct{}, error) {
+		return struct{}{}, writeInventory(dist)
+	})
+	return err
+}
+
+func writeInventory(dist Distribution) error {
+	components, err := Inventory(dist)
+	if err != nil {
+		return fmt.Errorf("failed to list the components of distribution %q: %w", dist.ID, err)
+	}
+
+	b, err := json.MarshalIndent(components, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path.Join(InventoryDir, fmt.Sprintf("%s_components.json", dist.ID)), append(b, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(path.Join(InventoryDir, fmt.Sprintf("%s_components.md", dist.ID)), inventoryMarkdown(dist, components), 0o644)
+}
+
+func inventoryMarkdown(dist Distribution, components []Component) []byte {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s components\n", dist.DisplayName())
+	kind := ""
+	for _, c := range components {
+		if c.Kind != kind {
+			kind = c.Kind
+			fmt.Fprintf(&sb, "\n## %s%s\n\n", strings.ToUpper(kind[:1]), kind[1:])
+			sb.WriteString("| Component | Module | Version | Stability |\n")
+			sb.WriteString("|-----------|--------|---------|-----------|\n")
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n", c.Type, c.Module, c.Version, formatStability(c.Stability))
+	}
+	return []byte(sb.String())
+}
+
+// formatStability renders stability levels such as "beta: logs; stable:
+// metrics, traces".
+func formatStability(stability map[string][]string) string {
+	if len(stability) == 0 {
+		return "unknown"
+	}
+	var levels []string
+	for level := range stability {
+		levels = append(levels, level)
+	}
+	sort.Strings(levels)
+	var parts []string
+	for _, level := range levels {
+		parts = append(parts, fmt.Sprintf("%s: %s", level, strings.Join(stability[level], ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
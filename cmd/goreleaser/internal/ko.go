@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// KoBaseImageRepository is the base image of the images built by ko, holding
+// the CA certificates and a nonroot user only. Like the apko images, it is
+// built from Wolfi packages, for ApkoArchitectures. The distributions pin it
+// by digest with ko_base_image, as its only tag is the mutable latest.
+const KoBaseImageRepository = "cgr.dev/chainguard/static"
+
+// koBaseImageRe matches the digest-pinned ko base images.
+var koBaseImageRe = regexp.MustCompile(`^` + regexp.QuoteMeta(KoBaseImageRepository) + `@sha256:[0-9a-f]{64}$`)
+
+// KoImages configures the images of the distributions built by ko instead of
+// docker buildx, one per image prefix. ko compiles the binary of the
+// distribution's build itself, from its generated sources, when publishing,
+// pushes the multi-arch images with their SBOMs, and needs no Dockerfile.
+// https://goreleaser.com/customization/ko/
+func KoImages(imagePrefixes []string, dists []Distribution, releases []string) (r []config.Ko) {
+	for _, dist := range dists {
+		if !dist.Release.Docker.Ko {
+			continue
+		}
+		for i, prefix := range imagePrefixes {
+			r = append(r, KoImage(fmt.Sprintf("%s-ko-%d", dist.ID, i), prefix, dist, releases))
+		}
+	}
+	return
+}
+
+// KoImage configures ko to build the images of a distribution to the
+// repository of the given prefix, tagged with its version and the mutable
+// tags the release moves among the release tags.
+func KoImage(id, prefix string, dist Distribution, releases []string) config.Ko {
+	var platforms []string
+	for _, p := range imagePlatforms(dist) {
+		if contains(ApkoArchitectures, p.arch) {
+			platforms = append(platforms, p.String())
+		}
+	}
+	tags := []string{"{{ .Version }}"}
+	for _, tag := range MovedTags(dist, releases) {
+		if tag != "latest" {
+			// ko leaves out the tags rendered empty.
+			tag = fmt.Sprintf("{{ if not .Prerelease }}%s{{ end }}", tag)
+		}
+		tags = append(tags, tag)
+	}
+	label := func(name string) string {
+		return "org.opencontainers.image." + name
+	}
+	return config.Ko{
+		ID:         id,
+		Build:      dist.ID,
+		BaseImage:  dist.Release.Docker.KoBaseImage,
+		Repository: fmt.Sprintf("%s/%s", prefix, imageName(dist)),
+		Bare:       true,
+		Platforms:  platforms,
+		Tags:       tags,
+		SBOM:       "spdx",
+		// The images get the commit's timestamp, like the other images.
+		CreationTime:       "{{ .CommitTimestamp }}",
+		KoDataCreationTime: "{{ .CommitTimestamp }}",
+		Labels: map[string]string{
+			label("created"):       "{{ .CommitDate }}",
+			label("name"):          "{{ .ProjectName }}",
+			label("revision"):      "{{ .FullCommit }}",
+			label("version"):       "{{ .Version }}",
+			label("source"):        "{{ .GitURL }}",
+			label("title"):         dist.DisplayName(),
+			label("description"):   dist.Description(),
+			label("url"):           dist.Homepage(),
+			label("licenses"):      dist.License(),
+			label("vendor"):        dist.Vendor(),
+			label("documentation"): dist.Documentation(),
+			label("base.name"):     dist.Release.Docker.KoBaseImage,
+		},
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the severity of a log entry.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+// ParseLevel parses a level name, such as "debug" or "info".
+func ParseLevel(name string) (Level, error) {
+	for level, n := range levelNames {
+		if strings.EqualFold(name, n) {
+			return level, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q, expected one of debug, info, warn or error", name)
+}
+
+// Logger writes leveled log entries with key-value attributes, either as
+// logfmt-like text or as JSON lines.
+type Logger struct {
+	mu    sync.Mutex
+	w     io.Writer
+	level Level
+	json  bool
+}
+
+// NewLogger returns a logger writing the entries of at least the given level
+// to w. The format is either "text" or "json".
+func NewLogger(w io.Writer, level Level, format string) (*Logger, error) {
+	switch format {
+	case "text":
+		return &Logger{w: w, level: level}, nil
+	case "json":
+		return &Logger{w: w, level: level, json: true}, nil
+	}
+	return nil, fmt.Errorf("unknown log format %q, expected text or json", format)
+}
+
+func (l *Logger) Debug(msg string, attrs ...any) { l.log(LevelDebug, msg, attrs) }
+func (l *Logger) Info(msg string, attrs ...any)  { l.log(LevelInfo, msg, attrs) }
+func (l *Logger) Warn(msg string, attrs ...any)  { l.log(LevelWarn, msg, attrs) }
+func (l *Logger) Error(msg string, attrs ...any) { l.log(LevelError, msg, attrs) }
+
+// Fatal logs an error and exits.
+func (l *Logger) Fatal(msg string, attrs ...any) {
+	l.log(LevelError, msg, attrs)
+	os.Exit(1)
+}
+
+// log writes an entry, attrs being alternating keys and values.
+func (l *Logger) log(level Level, msg string, attrs []any) {
+	if level < l.level {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if l.json {
+		entry := map[string]any{"time": now, "level": levelNames[level], "msg": msg}
+		for i := 0; i+1 < len(attrs); i += 2 {
+			value := attrs[i+1]
+			if err, ok := value.(error); ok {
+				value = err.Error()
+			}
+			entry[fmt.Sprint(attrs[i])] = value
+		}
+		b, _ := json.Marshal(entry)
+		fmt.Fprintf(l.w, "%s\n", b)
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "time=%s level=%s msg=%q", now, levelNames[level], msg)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		fmt.Fprintf(&sb, " %v=%q", attrs[i], fmt.Sprint(attrs[i+1]))
+	}
+	fmt.Fprintln(l.w, sb.String())
+}
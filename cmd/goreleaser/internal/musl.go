@@ -0,0 +1,84 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"strings"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// MuslCompilers are the musl C cross-compilers of the linux targets the musl
+// builds are made for, as <goos>/<goarch>, named after the musl.cc
+// toolchains. The arm one targets armv7, see MuslArmVersion.
+var MuslCompilers = map[string]string{
+	"linux/386":     "i686-linux-musl-gcc",
+	"linux/amd64":   "x86_64-linux-musl-gcc",
+	"linux/arm":     "armv7l-linux-musleabihf-gcc",
+	"linux/arm64":   "aarch64-linux-musl-gcc",
+	"linux/ppc64le": "powerpc64le-linux-musl-gcc",
+	"linux/riscv64": "riscv64-linux-musl-gcc",
+	"linux/s390x":   "s390x-linux-musl-gcc",
+}
+
+// MuslArmVersion is the only GOARM the musl builds are made for, the one of
+// the armv7 toolchain of MuslCompilers.
+const MuslArmVersion = "7"
+
+// MuslID is the ID of the build and archives of the distribution's musl
+// build.
+func (d Distribution) MuslID() string {
+	return d.ID + "-musl"
+}
+
+// MuslBuild configures the musl build of a cgo distribution, linked
+// statically against musl so that it runs without glibc, such as on Alpine.
+// It is made for the linux targets of the distribution with a musl
+// cross-compiler, and for armv7 only on arm.
+func MuslBuild(dist Distribution) config.Build {
+	b := Build(dist)
+	b.ID = dist.MuslID()
+	b.Goos = []string{"linux"}
+	var goarch []string
+	for _, arch := range b.Goarch {
+		if _, ok := MuslCompilers["linux/"+arch]; !ok {
+			continue
+		}
+		if arch == "arm" && !contains(b.Goarm, MuslArmVersion) {
+			continue
+		}
+		goarch = append(goarch, arch)
+	}
+	b.Goarch = goarch
+	b.Goarm = []string{MuslArmVersion}
+	b.Gomips = nil
+	b.Ignore = Ignore(dist, b.Goos, b.Goarch)
+	b.Tags = append(b.Tags, "netgo", "osusergo")
+	b.Ldflags = append(b.Ldflags, "-linkmode=external", "-extldflags=-static")
+	b.BuildDetailsOverrides = targetOverrides(dist, b, MuslCompilers)
+	return b
+}
+
+// MuslArchive configures the archives of the musl build, suffixed with
+// -musl, such as otelcol-musl_0.89.0_linux_amd64.tar.gz.
+func MuslArchive(dist Distribution) config.Archive {
+	return config.Archive{
+		ID:           dist.MuslID(),
+		NameTemplate: strings.Replace(archiveNameTemplate, "{{ .Binary }}", dist.BinaryName()+"-musl", 1),
+		Builds:       []string{dist.MuslID()},
+		BuildsInfo:   config.FileInfo{MTime: commitDate},
+		Files:        archiveFiles(),
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// BuildOverride adds build settings to some targets of a distribution, on
+// top of the ones of all its targets.
+type BuildOverride struct {
+	// Targets are the targets overridden, as <goos>/<goarch>, such as
+	// "windows/amd64", or as linux/arm/v<goarm> for a single arm version.
+	Targets []string `yaml:"targets"`
+	// Env are extra environment variables, as KEY=VALUE.
+	Env []string `yaml:"env"`
+	// Flags, Ldflags and Tags are extra go build flags, linker flags and
+	// build tags.
+	Flags   []string `yaml:"flags"`
+	Ldflags []string `yaml:"ldflags"`
+	Tags    []string `yaml:"tags"`
+}
+
+// validateOverrideTarget checks a target of the distribution's overrides,
+// which may be a default platform or an extra target.
+func validateOverrideTarget(target string) error {
+	if contains(OptInTargets, target) {
+		return nil
+	}
+	return validateExcludeTarget(target)
+}
+
+// targetOverrides are the overrides of a build's targets: the C
+// cross-compiler from compilers, if any, and the distribution's overrides
+// matching the target, added to the build's settings. goreleaser applies the
+// first override matching the exact target, arm version, amd64 level and
+// mips variant included, so there is one for each.
+// https://goreleaser.com/customization/build/
+func targetOverrides(dist Distribution, b config.Build, compilers map[string]string) (r []config.BuildDetailsOverride) {
+	ignored := map[string]bool{}
+	for _, i := range b.Ignore {
+		ignored[fmt.Sprintf("%s/%s/%s", i.Goos, i.Goarch, i.Goarm)] = true
+	}
+	// goreleaser's defaults, when the build sets none.
+	goarm, goamd64, gomips := b.Goarm, b.Goamd64, b.Gomips
+	if len(goarm) == 0 {
+		goarm = []string{"6"}
+	}
+	if len(goamd64) == 0 {
+		goamd64 = []string{"v1"}
+	}
+	if len(gomips) == 0 {
+		gomips = []string{"hardfloat"}
+	}
+
+	for _, goos := range b.Goos {
+		for _, goarch := range b.Goarch {
+			target := fmt.Sprintf("%s/%s", goos, goarch)
+			if ignored[target+"/"] {
+				continue
+			}
+			override := config.BuildDetailsOverride{Goos: goos, Goarch: goarch}
+			switch {
+			case goarch == ArmArch:
+				for _, armVersion := range goarm {
+					if ignored[fmt.Sprintf("%s/%s", target, armVersion)] {
+						continue
+					}
+					if details, ok := targetDetails(dist, b, compilers[target], target, fmt.Sprintf("%s/v%s", target, armVersion)); ok {
+						override.Goarm = armVersion
+						override.BuildDetails = details
+						r = append(r, override)
+					}
+				}
+			case goarch == "amd64":
+				if details, ok := targetDetails(dist, b, compilers[target], target); ok {
+					for _, level := range goamd64 {
+						override.Goamd64 = level
+						override.BuildDetails = details
+						r = append(r, override)
+					}
+				}
+			case strings.HasPrefix(goarch, "mips"):
+				if details, ok := targetDetails(dist, b, compilers[target], target); ok {
+					for _, variant := range gomips {
+						override.Gomips = variant
+						override.BuildDetails = details
+						r = append(r, override)
+					}
+				}
+			default:
+				if details, ok := targetDetails(dist, b, compilers[target], target); ok {
+					override.BuildDetails = details
+					r = append(r, override)
+				}
+			}
+		}
+	}
+	return
+}
+
+// targetDetails are the build settings of a target, or false when it keeps
+// the build's. The distribution's overrides apply when they list any of the
+// given targets, such as linux/arm or linux/arm/v7. goreleaser replaces the
+// build's flags with the override's, so those are added to the build's.
+func targetDetails(dist Distribution, b config.Build, compiler string, targets ...string) (details config.BuildDetails, ok bool) {
+	if compiler != "" {
+		details.Env = append(details.Env, "CC="+compiler)
+		ok = true
+	}
+	var flags, ldflags, tags []string
+	for _, o := range dist.Release.Overrides {
+		if !containsAny(o.Targets, targets) {
+			continue
+		}
+		ok = true
+		details.Env = append(details.Env, o.Env...)
+		flags = append(flags, o.Flags...)
+		ldflags = append(ldflags, o.Ldflags...)
+		tags = append(tags, o.Tags...)
+	}
+	if len(flags) > 0 {
+		details.Flags = append(append([]string{}, b.Flags...), flags...)
+	}
+	if len(ldflags) > 0 {
+		details.Ldflags = append(append([]string{}, b.Ldflags...), ldflags...)
+	}
+	if len(tags) > 0 {
+		details.Tags = append(append([]string{}, b.Tags...), tags...)
+	}
+	return
+}
+
+func containsAny(elems, values []string) bool {
+	for _, v := range values {
+		if contains(elems, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PlatformsFile is the supported-platform matrix attached to the releases.
+const PlatformsFile = "platforms.json"
+
+// PlatformMatrix lists the platforms each distribution is released for, and
+// the artifacts published for each of them.
+type PlatformMatrix struct {
+	Distributions []DistributionPlatforms `json:"distributions"`
+}
+
+// DistributionPlatforms are the platforms a distribution is released for.
+type DistributionPlatforms struct {
+	Name      string     `json:"name"`
+	Platforms []Platform `json:"platforms"`
+}
+
+// Platform is a target a distribution is released for. Artifacts are
+// "archive", the package formats, and "image".
+type Platform struct {
+	OS        string   `json:"os"`
+	Arch      string   `json:"arch"`
+	Arm       string   `json:"arm,omitempty"`
+	Amd64     string   `json:"amd64,omitempty"`
+	Mips      string   `json:"mips,omitempty"`
+	Artifacts []string `json:"artifacts"`
+}
+
+// Platforms returns the platform matrix of the given distributions, derived
+// from the same settings as the goreleaser configuration.
+func Platforms(dists []Distribution) PlatformMatrix {
+	var m PlatformMatrix
+	for _, dist := range dists {
+		m.Distributions = append(m.Distributions, DistributionPlatforms{
+			Name:      dist.ID,
+			Platforms: distributionPlatforms(dist),
+		})
+	}
+	return m
+}
+
+func distributionPlatforms(dist Distribution) []Platform {
+	goos, goarch, ignore := buildMatrix(dist)
+	ignored := map[string]bool{}
+	for _, i := range ignore {
+		if i.Goarm != "" {
+			ignored[fmt.Sprintf("%s/%s/v%s", i.Goos, i.Goarch, i.Goarm)] = true
+		} else {
+			ignored[fmt.Sprintf("%s/%s", i.Goos, i.Goarch)] = true
+		}
+	}
+	images := map[imagePlatform]bool{}
+	for _, p := range imagePlatforms(dist) {
+		if !dist.Release.Docker.Apko && !dist.Release.Docker.Ko || contains(ApkoArchitectures, p.arch) {
+			images[p] = true
+		}
+	}
+	gomips := dist.Release.Gomips
+	if len(gomips) == 0 {
+		gomips = []string{"hardfloat"}
+	}
+
+	var r []Platform
+	for _, o := range goos {
+		for _, arch := range goarch {
+			if ignored[fmt.Sprintf("%s/%s", o, arch)] {
+				continue
+			}
+			artifacts := []string{"archive"}
+			if o == "linux" {
+				artifacts = append(artifacts, Package(dist).Formats...)
+			}
+			withImage := func(armVersion string) []string {
+				if o == "linux" && images[imagePlatform{arch: arch, armVersion: armVersion}] {
+					return append(append([]string{}, artifacts...), "image")
+				}
+				return artifacts
+			}
+
+			switch {
+			case arch == ArmArch:
+				for _, armVersion := range dist.ArmVersions() {
+					if !ignored[fmt.Sprintf("%s/%s/v%s", o, arch, armVersion)] {
+						r = append(r, Platform{OS: o, Arch: arch, Arm: armVersion, Artifacts: withImage(armVersion)})
+					}
+				}
+			case strings.HasPrefix(arch, "mips"):
+				for _, mips := range gomips {
+					r = append(r, Platform{OS: o, Arch: arch, Mips: mips, Artifacts: artifacts})
+				}
+			case arch == "amd64":
+				r = append(r, Platform{OS: o, Arch: arch, Artifacts: withImage("")})
+				for _, level := range dist.Release.Goamd64 {
+					if level != "v1" {
+						r = append(r, Platform{OS: o, Arch: arch, Amd64: level, Artifacts: withImage("")})
+					}
+				}
+			default:
+				r = append(r, Platform{OS: o, Arch: arch, Artifacts: withImage("")})
+			}
+		}
+	}
+	if dist.Release.UniversalBinary {
+		r = append(r, Platform{OS: "darwin", Arch: "all", Artifacts: []string{"archive"}})
+	}
+	return r
+}
+
+// SplitMatrix is the GitHub Actions matrix of the release workflow, which
+// builds each GOOS and GOARCH pair in its own job with goreleaser's split by
+// target, or each GOOS when split by GOOS.
+type SplitMatrix struct {
+	Include []SplitTarget `json:"include"`
+}
+
+// SplitTarget is a job of the split matrix.
+type SplitTarget struct {
+	GOOS   string `json:"GOOS"`
+	GOARCH string `json:"GOARCH,omitempty"`
+}
+
+// ReleaseSplitMatrix returns the split matrix covering every platform the
+// given distributions are released for, including their extra targets.
+func ReleaseSplitMatrix(dists []Distribution) SplitMatrix {
+	byGoos := SplitBy(dists) == "goos"
+	seen := map[SplitTarget]bool{}
+	m := SplitMatrix{Include: []SplitTarget{}}
+	for _, dist := range dists {
+		for _, p := range distributionPlatforms(dist) {
+			// The universal binaries are merged by the darwin job.
+			if p.Arch == "all" {
+				continue
+			}
+			target := SplitTarget{GOOS: p.OS, GOARCH: p.Arch}
+			if byGoos {
+				target.GOARCH = ""
+			}
+			if !seen[target] {
+				seen[target] = true
+				m.Include = append(m.Include, target)
+			}
+		}
+	}
+	sort.Slice(m.Include, func(i, j int) bool {
+		a, b := m.Include[i], m.Include[j]
+		if a.GOOS != b.GOOS {
+			return a.GOOS < b.GOOS
+		}
+		return a.GOARCH < b.GOARCH
+	})
+	return m
+}
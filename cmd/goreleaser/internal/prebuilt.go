@@ -0,0 +1,104 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// PrebuiltBuilder is goreleaser-pro's builder importing binaries compiled
+// outside of goreleaser.
+// https://goreleaser.com/customization/builds/#import-pre-built-binaries
+const PrebuiltBuilder = "prebuilt"
+
+// prebuiltTargetDir is the directory of a target's binary in goreleaser's
+// dist directory, such as linux_amd64_v1 or linux_arm_7.
+const prebuiltTargetDir = `{{ .Os }}_{{ .Arch }}{{ with .Amd64 }}_{{ . }}{{ end }}{{ with .Arm }}_{{ . }}{{ end }}{{ with .Mips }}_{{ . }}{{ end }}`
+
+// Prebuilt configures where goreleaser-pro picks up the binaries of a
+// prebuilt build.
+type Prebuilt struct {
+	Path string `yaml:"path"`
+}
+
+// PrebuiltBuild turns a build into one importing its binaries. The targets
+// and hooks are kept, but there is nothing left to compile.
+func PrebuiltBuild(b config.Build) config.Build {
+	b.Builder = PrebuiltBuilder
+	b.Dir = ""
+	b.BuildDetails = config.BuildDetails{}
+	b.BuildDetailsOverrides = nil
+	return b
+}
+
+// Prebuilts are the settings of the prebuilt builds of the distributions, by
+// build ID. The binaries are picked up from
+// <prebuilt>/<build>_<target>/<binary>, as goreleaser lays them out in its
+// dist directory, so that the output of a goreleaser build run elsewhere can
+// be copied as is.
+func Prebuilts(dists []Distribution) map[string]Prebuilt {
+	r := map[string]Prebuilt{}
+	for _, dist := range dists {
+		if dist.Release.Prebuilt == "" {
+			continue
+		}
+		for _, b := range Builds([]Distribution{dist}) {
+			r[b.ID] = Prebuilt{
+				Path: path.Join(dist.Release.Prebuilt, b.ID+"_"+prebuiltTargetDir, b.Binary) + `{{ if eq .Os "windows" }}.exe{{ end }}`,
+			}
+		}
+	}
+	return r
+}
+
+// MarshalYAML encodes the project, adding the settings of the prebuilt
+// builds to the builds.
+func (p Project) MarshalYAML() (interface{}, error) {
+	// project has the fields of Project, but not its methods.
+	type project Project
+	var node yaml.Node
+	if err := node.Encode(project(p)); err != nil {
+		return nil, err
+	}
+	if len(p.Prebuilts) == 0 {
+		return &node, nil
+	}
+
+	builds := mappingValue(&node, "builds")
+	if builds == nil {
+		return &node, nil
+	}
+	for _, build := range builds.Content {
+		id := mappingValue(build, "id")
+		if id == nil {
+			continue
+		}
+		prebuilt, ok := p.Prebuilts[id.Value]
+		if !ok {
+			continue
+		}
+		var key, value yaml.Node
+		key.SetString("prebuilt")
+		if err := value.Encode(prebuilt); err != nil {
+			return nil, fmt.Errorf("failed to encode the prebuilt settings of build %q: %w", id.Value, err)
+		}
+		build.Content = append(build.Content, &key, &value)
+	}
+	return &node, nil
+}
@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// ReleaseTags are the release tags of the repository, such as v0.89.0.
+func ReleaseTags() ([]string, error) {
+	out, err := exec.Command("git", "tag", "--list", "v*").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the tags: %w", err)
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// PreviousRelease is the stable release preceding tag in its release line:
+// the latest one of the same major and minor version, or the latest one
+// below when tag starts the line. Releasing 0.88.2 after 0.89.0 thus
+// compares it with 0.88.1. It is empty when no release precedes tag.
+func PreviousRelease(tag string, tags []string) string {
+	var previous, previousInLine string
+	for _, t := range tags {
+		if !semver.IsValid(t) || semver.Prerelease(t) != "" || semver.Compare(t, tag) >= 0 {
+			continue
+		}
+		if previous == "" || semver.Compare(t, previous) > 0 {
+			previous = t
+		}
+		if semver.MajorMinor(t) == semver.MajorMinor(tag) && (previousInLine == "" || semver.Compare(t, previousInLine) > 0) {
+			previousInLine = t
+		}
+	}
+	if previousInLine != "" {
+		return previousInLine
+	}
+	return previous
+}
+
+// LatestInLine reports whether version, such as v0.89.0, is at least the
+// latest stable release of a line among the release tags. The line is a
+// version prefix, such as v0.89 or v0, or all the releases when empty.
+func LatestInLine(version, line string, tags []string) bool {
+	for _, t := range tags {
+		if !semver.IsValid(t) || semver.Prerelease(t) != "" {
+			continue
+		}
+		if line != "" && semver.MajorMinor(t) != line && semver.Major(t) != line {
+			continue
+		}
+		if semver.Compare(t, version) > 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectsFile lists several goreleaser projects to generate in a single
+// invocation. Every project includes the common settings, which sibling
+// repositories releasing related binaries, such as ocb or the OpAMP
+// supervisor, can include as well.
+type ProjectsFile struct {
+	// Common is the path of the configuration file holding the common
+	// settings. Like every path of this file, it is relative to the root of
+	// the repository, from which goreleaser runs.
+	Common   string              `yaml:"common"`
+	Projects []ProjectDefinition `yaml:"projects"`
+}
+
+// ProjectDefinition is a goreleaser project releasing some of the
+// distributions of this repository.
+type ProjectDefinition struct {
+	Name          string   `yaml:"name"`
+	Output        string   `yaml:"output"`
+	Distributions []string `yaml:"distributions"`
+}
+
+// LoadProjectsFile reads the definitions of the projects to generate.
+func LoadProjectsFile(file string) (ProjectsFile, error) {
+	var pf ProjectsFile
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return pf, err
+	}
+	if err := yaml.Unmarshal(b, &pf); err != nil {
+		return pf, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	if pf.Common == "" {
+		return pf, fmt.Errorf("%s: no path for the common configuration", file)
+	}
+	for _, p := range pf.Projects {
+		if p.Name == "" || p.Output == "" || len(p.Distributions) == 0 {
+			return pf, fmt.Errorf("%s: projects need a name, an output and distributions", file)
+		}
+	}
+	return pf, nil
+}
+
+// WriteProjects generates the common configuration and every project of the
+// projects file, moving the mutable image tags per the release tags.
+func WriteProjects(pf ProjectsFile, imagePrefixes, releases []string) error {
+	common := Common()
+	if err := writeYAML(pf.Common, "", &common); err != nil {
+		return err
+	}
+
+	for _, def := range pf.Projects {
+		dists, err := LoadDistributions(def.Distributions)
+		if err != nil {
+			return err
+		}
+		if err := ValidateDockerfiles(dists); err != nil {
+			return err
+		}
+		if err := CheckToolchainPins(dists); err != nil {
+			return err
+		}
+		project := DistributionsProject(def.Name, imagePrefixes, dists, releases)
+		project.Includes = []Include{{FromFile: IncludeFromFile{Path: pf.Common}}}
+
+		header, err := Header(dists)
+		if err != nil {
+			return err
+		}
+		if err := writeYAML(def.Output, header, &project); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeYAML(file, header string, v interface{}) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(header); err != nil {
+		f.Close()
+		return err
+	}
+	if err := yaml.NewEncoder(f).Encode(v); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,100 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// GeneratorVersion identifies the generator in the header of the files it
+// writes: the version of its module when built from a release tag, such as
+// with go install, or else the release version of the given distributions,
+// the newest one if they differ, the generator being released with them.
+func GeneratorVersion(dists []Distribution) string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if v := info.Main.Version; semver.IsValid(v) && semver.Build(v) == "" && !module.IsPseudoVersion(v) {
+			return v
+		}
+	}
+	var version string
+	for _, dist := range dists {
+		if v := "v" + strings.TrimPrefix(dist.Dist.Version, "v"); version == "" || compareVersions(v, version) > 0 {
+			version = v
+		}
+	}
+	return version
+}
+
+// manifestsHashPrefix starts the header line holding the manifests hash.
+const manifestsHashPrefix = "# Manifests: sha256:"
+
+// Header is the comment heading the goreleaser configuration generated for
+// the given distributions. It records the generator version and a hash of
+// their manifests, telling stale configurations from hand-edited ones.
+func Header(dists []Distribution) (string, error) {
+	hash, err := ManifestsHash(dists)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`# Code generated by cmd/goreleaser %s. DO NOT EDIT.
+# Regenerate it with `+"`make generate-goreleaser`"+`.
+%s%s
+`, GeneratorVersion(dists), manifestsHashPrefix, hash), nil
+}
+
+// ManifestsHash hashes the manifests of the given distributions, in the
+// order of their IDs.
+func ManifestsHash(dists []Distribution) (string, error) {
+	ids := make([]string, 0, len(dists))
+	for _, dist := range dists {
+		ids = append(ids, dist.ID)
+	}
+	sort.Strings(ids)
+
+	h := sha256.New()
+	for _, id := range ids {
+		b, err := os.ReadFile(path.Join("distributions", id, "manifest.yaml"))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s %d\n", id, len(b))
+		h.Write(b)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HeaderManifestsHash returns the manifests hash recorded in the header of a
+// generated configuration, or an empty string if it has none.
+func HeaderManifestsHash(b []byte) string {
+	for _, line := range strings.Split(string(b), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		if strings.HasPrefix(line, manifestsHashPrefix) {
+			return strings.TrimPrefix(line, manifestsHashPrefix)
+		}
+	}
+	return ""
+}
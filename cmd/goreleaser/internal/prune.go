@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GitHubRelease is a release of the GitHub repository, as listed by the
+// GitHub CLI.
+type GitHubRelease struct {
+	TagName   string    `json:"tagName"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ListNightlyReleases lists the nightly and snapshot releases of the
+// repository, newest first.
+func ListNightlyReleases(repo string) ([]GitHubRelease, error) {
+	out, err := exec.Command("gh", "release", "list", "--repo", repo, "--limit", "10000", "--json", "tagName,createdAt").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the releases of %s: %w", repo, ghError(err))
+	}
+	var releases []GitHubRelease
+	if err := json.Unmarshal(out, &releases); err != nil {
+		return nil, fmt.Errorf("failed to parse the releases of %s: %w", repo, err)
+	}
+	var r []GitHubRelease
+	for _, release := range releases {
+		if ReleaseChannel(release.TagName) == NightlyChannel {
+			r = append(r, release)
+		}
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].CreatedAt.After(r[j].CreatedAt) })
+	return r, nil
+}
+
+// ReleasesToPrune returns the releases older than the retention window,
+// always keeping the newest keep ones. releases are sorted newest first.
+func ReleasesToPrune(releases []GitHubRelease, keep int, retention time.Duration, now time.Time) []GitHubRelease {
+	var r []GitHubRelease
+	for i, release := range releases {
+		if i < keep || now.Sub(release.CreatedAt) < retention {
+			continue
+		}
+		r = append(r, release)
+	}
+	return r
+}
+
+// DeleteRelease deletes a release, along with its assets and its tag.
+func DeleteRelease(repo, tag string) error {
+	if err := exec.Command("gh", "release", "delete", tag, "--repo", repo, "--yes", "--cleanup-tag").Run(); err != nil {
+		return fmt.Errorf("failed to delete the release %s of %s: %w", tag, repo, ghError(err))
+	}
+	return nil
+}
+
+// ghError adds the output of the GitHub CLI to its error.
+func ghError(err error) error {
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return err
+}
@@ -0,0 +1,223 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"golang.org/x/mod/semver"
+)
+
+// SBOMSuffix is the suffix of the SPDX documents goreleaser generates
+// for each artifact.
+const SBOMSuffix = ".sbom.json"
+
+// ImageSBOMScript catalogs an image with syft and attaches its SBOM to it as
+// a cosign attestation. goreleaser expands the variables of the signing
+// commands, so it can't be inlined.
+const ImageSBOMScript = "./scripts/attest-sbom.sh"
+
+// SBOMs configures an SPDX document cataloged by syft for each archive,
+// published with the release as <archive>.sbom.json.
+// https://goreleaser.com/customization/sbom/
+func SBOMs() []config.SBOM {
+	return []config.SBOM{
+		{
+			Cmd:       "syft",
+			Artifacts: "archive",
+			Documents: []string{"{{ .ArtifactName }}" + SBOMSuffix},
+		},
+	}
+}
+
+// ImageSBOMs configures the SBOMs of the images, which goreleaser doesn't
+// catalog: each image is cataloged once pushed, and its SBOM attached to it
+// in the registries, where `cosign verify-attestation --type spdxjson`
+// retrieves it. Manifest lists are left out, their images being cataloged
+// one by one.
+func ImageSBOMs() []config.Sign {
+	return []config.Sign{
+		{
+			ID:        "sbom",
+			Cmd:       ImageSBOMScript,
+			Args:      []string{"${artifact}"},
+			Artifacts: "images",
+		},
+	}
+}
+
+// spdxDocument is the part of an SPDX JSON document needed to diff
+// dependencies.
+type spdxDocument struct {
+	Packages []struct {
+		Name        string `json:"name"`
+		VersionInfo string `json:"versionInfo"`
+	} `json:"packages"`
+}
+
+// DependencyChange is a dependency whose version differs between releases.
+type DependencyChange struct {
+	Name       string
+	OldVersion string
+	NewVersion string
+}
+
+// SBOMDiff lists the dependency changes of a distribution between two
+// releases.
+type SBOMDiff struct {
+	Dist       string
+	Added      []DependencyChange
+	Removed    []DependencyChange
+	Upgraded   []DependencyChange
+	Downgraded []DependencyChange
+}
+
+// DiffSBOMs compares the SBOMs of each distribution found in oldDir and
+// newDir. The dependencies of a distribution are the union of the packages
+// listed by all its SBOMs, which are the files named "<dist>_*.sbom.json".
+func DiffSBOMs(oldDir, newDir string, dists []string) ([]SBOMDiff, error) {
+	var r []SBOMDiff
+	for _, dist := range dists {
+		oldDeps, err := readDependencies(oldDir, dist)
+		if err != nil {
+			return nil, err
+		}
+		newDeps, err := readDependencies(newDir, dist)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, diffDependencies(dist, oldDeps, newDeps))
+	}
+	return r, nil
+}
+
+func readDependencies(dir, dist string) (map[string]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, fmt.Sprintf("%s_*%s", dist, SBOMSuffix)))
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no SBOM found for distribution %q in %s", dist, dir)
+	}
+
+	deps := map[string]string{}
+	for _, match := range matches {
+		b, err := os.ReadFile(match)
+		if err != nil {
+			return nil, err
+		}
+		var doc spdxDocument
+		if err := json.Unmarshal(b, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse SBOM %s: %w", match, err)
+		}
+		for _, pkg := range doc.Packages {
+			if pkg.Name == "" || pkg.VersionInfo == "" {
+				continue
+			}
+			if current, ok := deps[pkg.Name]; !ok || compareVersions(pkg.VersionInfo, current) > 0 {
+				deps[pkg.Name] = pkg.VersionInfo
+			}
+		}
+	}
+	return deps, nil
+}
+
+func diffDependencies(dist string, oldDeps, newDeps map[string]string) SBOMDiff {
+	d := SBOMDiff{Dist: dist}
+	for name, newVersion := range newDeps {
+		oldVersion, ok := oldDeps[name]
+		change := DependencyChange{Name: name, OldVersion: oldVersion, NewVersion: newVersion}
+		switch {
+		case !ok:
+			d.Added = append(d.Added, change)
+		case compareVersions(newVersion, oldVersion) > 0:
+			d.Upgraded = append(d.Upgraded, change)
+		case compareVersions(newVersion, oldVersion) < 0:
+			d.Downgraded = append(d.Downgraded, change)
+		}
+	}
+	for name, oldVersion := range oldDeps {
+		if _, ok := newDeps[name]; !ok {
+			d.Removed = append(d.Removed, DependencyChange{Name: name, OldVersion: oldVersion})
+		}
+	}
+
+	for _, changes := range [][]DependencyChange{d.Added, d.Removed, d.Upgraded, d.Downgraded} {
+		sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	}
+	return d
+}
+
+// compareVersions compares two versions such as "v0.89.0" or "1.2.3-rc.1"
+// by semantic versioning precedence, a prerelease preceding its release. The
+// versions that aren't semantic are compared as strings.
+func compareVersions(a, b string) int {
+	canonical := func(v string) string {
+		if !strings.HasPrefix(v, "v") {
+			return "v" + v
+		}
+		return v
+	}
+	a, b = canonical(a), canonical(b)
+	if !semver.IsValid(a) || !semver.IsValid(b) {
+		return strings.Compare(a, b)
+	}
+	return semver.Compare(a, b)
+}
+
+// WriteSBOMDiffReport writes the diffs as a Markdown document, meant to be
+// appended to the release notes.
+func WriteSBOMDiffReport(w io.Writer, diffs []SBOMDiff) error {
+	var sb strings.Builder
+	sb.WriteString("## Dependency changes\n")
+	for _, d := range diffs {
+		fmt.Fprintf(&sb, "\n### %s\n", d.Dist)
+		if len(d.Added)+len(d.Removed)+len(d.Upgraded)+len(d.Downgraded) == 0 {
+			sb.WriteString("\nNo dependency changes.\n")
+			continue
+		}
+		writeChanges(&sb, "Added", d.Added, func(c DependencyChange) string {
+			return fmt.Sprintf("%s %s", c.Name, c.NewVersion)
+		})
+		writeChanges(&sb, "Removed", d.Removed, func(c DependencyChange) string {
+			return fmt.Sprintf("%s %s", c.Name, c.OldVersion)
+		})
+		writeChanges(&sb, "Upgraded", d.Upgraded, func(c DependencyChange) string {
+			return fmt.Sprintf("%s %s → %s", c.Name, c.OldVersion, c.NewVersion)
+		})
+		writeChanges(&sb, "Downgraded", d.Downgraded, func(c DependencyChange) string {
+			return fmt.Sprintf("%s %s → %s", c.Name, c.OldVersion, c.NewVersion)
+		})
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeChanges(sb *strings.Builder, title string, changes []DependencyChange, format func(DependencyChange) string) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "\n#### %s\n\n", title)
+	for _, c := range changes {
+		fmt.Fprintf(sb, "- %s\n", format(c))
+	}
+}
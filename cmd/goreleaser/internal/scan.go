@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import "fmt"
+
+const (
+	// ImageScanScript scans a locally built image with trivy, or grype, and
+	// fails when it has fixable vulnerabilities of ImageScanSeverity or
+	// above.
+	ImageScanScript = "./scripts/scan-image.sh"
+
+	// ImageScanSeverity is the lowest severity of the vulnerabilities
+	// failing the release.
+	ImageScanSeverity = "critical"
+)
+
+// BeforePublishHook makes goreleaser-pro run a command on each artifact of
+// the given type before publishing anything.
+// https://goreleaser.com/customization/beforepublish/
+type BeforePublishHook struct {
+	Cmd       string `yaml:"cmd"`
+	Artifacts string `yaml:"artifacts,omitempty"`
+	Output    bool   `yaml:"output,omitempty"`
+}
+
+// ImageScans gate the release on the vulnerabilities of the images: each
+// image built by docker buildx is scanned once built, and a vulnerable one
+// fails the release before any artifact is pushed. The images built by ko
+// and apko are built while being published, and aren't scanned.
+func ImageScans() []BeforePublishHook {
+	return []BeforePublishHook{
+		{
+			Cmd:       fmt.Sprintf("%s %s {{ .ArtifactName }}", ImageScanScript, ImageScanSeverity),
+			Artifacts: "image",
+			Output:    true,
+		},
+	}
+}
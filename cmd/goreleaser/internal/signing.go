@@ -0,0 +1,55 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// Signs configures the signature of the checksums file with cosign, which
+// covers the archives and packages it lists. The signature is keyless: cosign
+// gets a short-lived certificate for the OIDC identity of the release
+// workflow, published next to the signature.
+// https://goreleaser.com/customization/sign/
+func Signs() []config.Sign {
+	return []config.Sign{
+		{
+			Cmd:         "cosign",
+			Certificate: "${artifact}.pem",
+			Args: []string{
+				"sign-blob",
+				"--yes",
+				"--output-signature=${signature}",
+				"--output-certificate=${certificate}",
+				"${artifact}",
+			},
+			Artifacts: "checksum",
+		},
+	}
+}
+
+// DockerSigns configures the keyless signature of every image and manifest
+// with cosign, pushed to the registries next to them, for admission
+// controllers to verify the images of the distributions.
+// https://goreleaser.com/customization/docker_sign/
+func DockerSigns() []config.Sign {
+	return []config.Sign{
+		{
+			Cmd:       "cosign",
+			Args:      []string{"sign", "--yes", "${artifact}"},
+			Artifacts: "all",
+		},
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ProvenanceDir is the directory the subjects of the SLSA provenance are
+// written to.
+const ProvenanceDir = "_provenance"
+
+// Files of ProvenanceDir, read by the release workflow.
+const (
+	// ProvenanceSubjectsFile lists the binaries and archives, in the format
+	// of sha256sum.
+	ProvenanceSubjectsFile = "subjects.sha256"
+	// ProvenanceImagesFile lists the image manifests, as a JSON array of
+	// ImageSubject.
+	ProvenanceImagesFile = "images.json"
+)
+
+// ImageSubject is an image manifest the provenance is attested for.
+type ImageSubject struct {
+	Name   string `json:"name"`
+	Digest string `json:"digest"`
+}
+
+// ProvenanceSubjects are the artifacts of a release the SLSA provenance
+// covers.
+type ProvenanceSubjects struct {
+	// Files are the checksums of the binaries and archives, by name. The
+	// binaries are named after their path in the dist directory, their
+	// names being shared by all the targets.
+	Files map[string]string
+	// Images are the multi-arch manifests, which reference the images of
+	// each platform.
+	Images []ImageSubject
+}
+
+// BuildProvenanceSubjects reads the release published by goreleaser from
+// distDir and lists the binaries, archives and image manifests of the given
+// distributions. The manifests have a digest once pushed only.
+func BuildProvenanceSubjects(distDir string, dists []Distribution) (ProvenanceSubjects, error) {
+	var artifacts []releaseArtifact
+	if err := readJSON(filepath.Join(distDir, "artifacts.json"), &artifacts); err != nil {
+		return ProvenanceSubjects{}, err
+	}
+
+	subjects := ProvenanceSubjects{Files: map[string]string{}}
+	seen := map[ImageSubject]bool{}
+	for _, dist := range dists {
+		ids := artifactIDs(dist)
+		for _, a := range artifacts {
+			switch a.Type {
+			case "Binary", "Archive":
+				if !contains(ids, a.Extra.ID) {
+					continue
+				}
+				name := a.Name
+				if a.Type == "Binary" {
+					rel, err := filepath.Rel(distDir, a.Path)
+					if err != nil {
+						return ProvenanceSubjects{}, err
+					}
+					name = filepath.ToSlash(rel)
+				}
+				sum := strings.TrimPrefix(a.Extra.Checksum, "sha256:")
+				if sum == "" {
+					b, err := os.ReadFile(a.Path)
+					if err != nil {
+						return ProvenanceSubjects{}, fmt.Errorf("failed to checksum %s: %w", a.Name, err)
+					}
+					sum = checksum(b)
+				}
+				subjects.Files[name] = sum
+			case "Docker Manifest":
+				repository, _, _ := strings.Cut(a.Name, ":")
+				if path.Base(repository) != imageName(dist) || a.Extra.Digest == "" {
+					continue
+				}
+				image := ImageSubject{Name: registryName(repository), Digest: a.Extra.Digest}
+				if !seen[image] {
+					seen[image] = true
+					subjects.Images = append(subjects.Images, image)
+				}
+			}
+		}
+	}
+	if len(subjects.Files) == 0 {
+		return ProvenanceSubjects{}, fmt.Errorf("no binary or archive of the distributions found in %s", distDir)
+	}
+	sort.Slice(subjects.Images, func(i, j int) bool {
+		if subjects.Images[i].Name != subjects.Images[j].Name {
+			return subjects.Images[i].Name < subjects.Images[j].Name
+		}
+		return subjects.Images[i].Digest < subjects.Images[j].Digest
+	})
+	return subjects, nil
+}
+
+// registryName qualifies a repository with its registry, Docker Hub when it
+// has none, as attestations are pushed to the registry of their subject.
+func registryName(repository string) string {
+	if host, _, ok := strings.Cut(repository, "/"); ok && strings.ContainsAny(host, ".:") {
+		return repository
+	}
+	return path.Join("docker.io", repository)
+}
+
+// WriteProvenanceSubjects writes the subjects to ProvenanceDir.
+func WriteProvenanceSubjects(subjects ProvenanceSubjects) error {
+	if err := os.MkdirAll(ProvenanceDir, 0o755); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(subjects.Files))
+	for name := range subjects.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sums strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&sums, "%s  %s\n", subjects.Files[name], name)
+	}
+	if err := os.WriteFile(path.Join(ProvenanceDir, ProvenanceSubjectsFile), []byte(sums.String()), 0o644); err != nil {
+		return err
+	}
+
+	images := subjects.Images
+	if images == nil {
+		images = []ImageSubject{}
+	}
+	b, err := json.Marshal(images)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path.Join(ProvenanceDir, ProvenanceImagesFile), append(b, '\n'), 0o644)
+}
@@ -0,0 +1,155 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//go:embed templates/version.go.tmpl
+var versionTemplate string
+
+var versionTmpl = template.Must(template.New("version.go").Parse(versionTemplate))
+
+// mainBuildInfo matches the fields ocb writes into the build info of the
+// generated main.go, or the variables they are stamped with, by variable.
+var mainBuildInfo = map[string]*regexp.Regexp{
+	"command":     regexp.MustCompile(`(?m)^(\s*Command:\s*)("[^"]*"|command),`),
+	"description": regexp.MustCompile(`(?m)^(\s*Description:\s*)("[^"]*"|description),`),
+	"version":     regexp.MustCompile(`(?m)^(\s*Version:\s*)("[^"]*"|version),`),
+}
+
+// buildInfo is the build info stamped into the generated sources.
+type buildInfo struct {
+	Command     string
+	Description string
+	Version     string
+}
+
+// VersionLdflags set the version variables of the generated sources to the
+// ones of the release. The date is the one of the commit, so that the
+// binaries are reproducible.
+var VersionLdflags = []string{
+	"-X main.version={{ .Version }}",
+	"-X main.commit={{ .FullCommit }}",
+	"-X main.date={{ .CommitDate }}",
+}
+
+// ReleaseCommit holds the goreleaser template fields of VersionLdflags, for
+// the binaries built outside goreleaser.
+type ReleaseCommit struct {
+	Version    string
+	FullCommit string
+	CommitDate string
+}
+
+// HeadCommit is the release commit of the checkout, with the given version.
+// The date is formatted like goreleaser's.
+func HeadCommit(version string) (ReleaseCommit, error) {
+	out, err := exec.Command("git", "log", "-1", "--format=%H %cI").Output()
+	if err != nil {
+		return ReleaseCommit{}, fmt.Errorf("failed to read the commit: %w", err)
+	}
+	commit, date, _ := strings.Cut(strings.TrimSpace(string(out)), " ")
+	t, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		return ReleaseCommit{}, fmt.Errorf("failed to parse the commit date: %w", err)
+	}
+	return ReleaseCommit{
+		Version:    strings.TrimPrefix(version, "v"),
+		FullCommit: commit,
+		CommitDate: t.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// Ldflags renders VersionLdflags for the commit.
+func (c ReleaseCommit) Ldflags() ([]string, error) {
+	var r []string
+	for _, flag := range VersionLdflags {
+		var buf bytes.Buffer
+		if err := template.Must(template.New("ldflag").Parse(flag)).Execute(&buf, c); err != nil {
+			return nil, err
+		}
+		r = append(r, buf.String())
+	}
+	return r, nil
+}
+
+// WriteVersionStamps makes the generated sources of the distributions, and
+// of their build variants, report the version set by the ldflags of the
+// release builds rather than the one of the manifest, and the command and
+// description of their branding: the binary name and the display name, which
+// ocb takes from the manifest's dist section otherwise. It must run after ocb
+// generated the sources and before they are compiled.
+func WriteVersionStamps(dists []Distribution) error {
+	_, err := forEach(dists, func(dist Distribution) (struct{}, error) {
+		info := buildInfo{
+			Command:     dist.BinaryName(),
+			Description: dist.Dist.Description,
+			Version:     dist.Dist.Version,
+		}
+		if dist.Release.Branding.DisplayName != "" {
+			info.Description = dist.DisplayName()
+		}
+		if err := stampBuildInfo(path.Join("distributions", dist.ID, "_build"), info); err != nil {
+			return struct{}{}, fmt.Errorf("failed to stamp the version of distribution %q: %w", dist.ID, err)
+		}
+		for _, variant := range dist.Release.BuildVariants {
+			info := info
+			info.Command = dist.VariantBinaryName(variant)
+			if err := stampBuildInfo(path.Join("distributions", dist.ID, VariantBuildDir(variant)), info); err != nil {
+				return struct{}{}, fmt.Errorf("failed to stamp the version of distribution %q: %w", dist.ID, err)
+			}
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+func stampBuildInfo(dir string, info buildInfo) error {
+	mainFile := path.Join(dir, "main.go")
+	b, err := os.ReadFile(mainFile)
+	if os.IsNotExist(err) {
+		// Build variants may not be generated yet.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for variable, field := range mainBuildInfo {
+		if !field.Match(b) {
+			return fmt.Errorf("no build info %s found in %s", variable, mainFile)
+		}
+		b = field.ReplaceAll(b, []byte("${1}"+variable+","))
+	}
+	if err := os.WriteFile(mainFile, b, 0o644); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := versionTmpl.Execute(&buf, info); err != nil {
+		return err
+	}
+	return os.WriteFile(path.Join(dir, "version.go"), buf.Bytes(), 0o644)
+}
@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ocbMain is the build info of the main.go generated by ocb.
+const ocbMain = `package main
+
+func main() {
+	info := component.BuildInfo{
+		Command:     "%s",
+		Description: "OpenTelemetry Collector",
+		Version:     "0.89.0",
+	}
+	run(info)
+}
+`
+
+// TestWriteVersionStampsBranding stamps the sources of a branded
+// distribution and of its build variant, and checks that their binaries
+// report the branded command and description.
+func TestWriteVersionStampsBranding(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	dist := Distribution{
+		ID:   "otelcol",
+		Dist: DistConfig{Name: "otelcol", Description: "OpenTelemetry Collector", Version: "0.89.0"},
+	}
+	dist.Release.Branding = Branding{DisplayName: "ACME Telemetry Collector", BinaryName: "acme-collector"}
+	variant := BuildVariant{Name: "fips"}
+	dist.Release.BuildVariants = []BuildVariant{variant}
+
+	dirs := map[string]string{
+		filepath.Join("distributions", "otelcol", "_build"):                 "acme-collector",
+		filepath.Join("distributions", "otelcol", VariantBuildDir(variant)): "acme-collector-fips",
+	}
+	for dir, command := range dirs {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		main := fmt.Sprintf(ocbMain, strings.Replace(command, "acme-collector", "otelcol", 1))
+		if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(main), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Stamping again must keep the sources stamped.
+	for i := 0; i < 2; i++ {
+		if err := WriteVersionStamps([]Distribution{dist}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for dir, command := range dirs {
+		main, err := os.ReadFile(filepath.Join(dir, "main.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, field := range []string{"Command:     command,", "Description: description,", "Version:     version,"} {
+			if !strings.Contains(string(main), field) {
+				t.Errorf("%s: main.go doesn't contain %q:\n%s", dir, field, main)
+			}
+		}
+
+		versionFile := filepath.Join(dir, "version.go")
+		version, err := os.ReadFile(versionFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := parser.ParseFile(token.NewFileSet(), versionFile, version, 0); err != nil {
+			t.Fatalf("%s: %v", versionFile, err)
+		}
+		for _, want := range []string{
+			`command     = "` + command + `"`,
+			`description = "ACME Telemetry Collector"`,
+			`version = "0.89.0"`,
+		} {
+			if !strings.Contains(string(version), want) {
+				t.Errorf("%s: version.go doesn't contain %q:\n%s", dir, want, version)
+			}
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GenerationSummary describes what a generator run changed in its output,
+// for CI logs and bots.
+type GenerationSummary struct {
+	Output string `json:"output"`
+	// Status is "created", "updated" or "unchanged".
+	Status   string          `json:"status"`
+	Sections []SectionChange `json:"sections,omitempty"`
+}
+
+// SectionChange is a top-level section of the goreleaser configuration
+// changed by a generator run.
+type SectionChange struct {
+	Name string `json:"name"`
+	// Reason is "added", "removed" or "changed".
+	Reason string `json:"reason"`
+	// Distributions are the distributions whose entries in the section
+	// changed, when the section lists per-distribution entries.
+	Distributions []string `json:"distributions,omitempty"`
+}
+
+// Summarize compares the previous and current contents of a generated
+// goreleaser configuration. previous is nil when the output didn't exist.
+func Summarize(output string, previous, current []byte, dists []Distribution) (GenerationSummary, error) {
+	s := GenerationSummary{Output: output}
+	switch {
+	case previous == nil:
+		s.Status = "created"
+	case bytes.Equal(previous, current):
+		s.Status = "unchanged"
+		return s, nil
+	default:
+		s.Status = "updated"
+	}
+
+	var prev, cur map[string]any
+	if err := yaml.Unmarshal(previous, &prev); err != nil {
+		return s, fmt.Errorf("failed to parse the previous %s: %w", output, err)
+	}
+	if err := yaml.Unmarshal(current, &cur); err != nil {
+		return s, err
+	}
+
+	var sections []string
+	for name := range cur {
+		sections = append(sections, name)
+	}
+	for name := range prev {
+		if _, ok := cur[name]; !ok {
+			sections = append(sections, name)
+		}
+	}
+	sort.Strings(sections)
+
+	for _, name := range sections {
+		p, inPrev := prev[name]
+		c, inCur := cur[name]
+		change := SectionChange{Name: name}
+		switch {
+		case !inPrev:
+			change.Reason = "added"
+		case !inCur:
+			change.Reason = "removed"
+		case reflect.DeepEqual(p, c):
+			continue
+		default:
+			change.Reason = "changed"
+		}
+		change.Distributions = changedDistributions(p, c, dists)
+		s.Sections = append(s.Sections, change)
+	}
+	return s, nil
+}
+
+// changedDistributions returns the distributions owning the entries that
+// differ between two versions of a section.
+func changedDistributions(previous, current any, dists []Distribution) []string {
+	prevEntries, currEntries := sectionEntries(previous), sectionEntries(current)
+	owners := map[string]bool{}
+	for key, entry := range currEntries {
+		if !reflect.DeepEqual(prevEntries[key], entry) {
+			owners[entryDistribution(entry, dists)] = true
+		}
+	}
+	for key, entry := range prevEntries {
+		if _, ok := currEntries[key]; !ok {
+			owners[entryDistribution(entry, dists)] = true
+		}
+	}
+	delete(owners, "")
+
+	var r []string
+	for owner := range owners {
+		r = append(r, owner)
+	}
+	sort.Strings(r)
+	return r
+}
+
+// sectionEntries indexes the entries of a list section by their identity.
+func sectionEntries(section any) map[string]map[string]any {
+	r := map[string]map[string]any{}
+	list, ok := section.([]any)
+	if !ok {
+		return r
+	}
+	for i, item := range list {
+		entry, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		key := fmt.Sprint(i)
+		for _, field := range []string{"id", "name_template", "name"} {
+			if v, ok := entry[field].(string); ok {
+				key = v
+				break
+			}
+		}
+		if images, ok := entry["image_templates"].([]any); ok && len(images) > 0 && entry["name_template"] == nil {
+			key = fmt.Sprint(images[0])
+		}
+		r[key] = entry
+	}
+	return r
+}
+
+// entryDistribution returns the distribution owning an entry of a section,
+// or an empty string when it isn't specific to a distribution.
+func entryDistribution(entry map[string]any, dists []Distribution) string {
+	var ids []string
+	if id, ok := entry["id"].(string); ok {
+		ids = append(ids, id)
+	}
+	if list, ok := entry["ids"].([]any); ok {
+		for _, id := range list {
+			ids = append(ids, fmt.Sprint(id))
+		}
+	}
+	image := ""
+	if name, ok := entry["name_template"].(string); ok {
+		name = name[strings.LastIndex(name, "/")+1:]
+		image, _, _ = strings.Cut(name, ":")
+	}
+
+	for _, dist := range dists {
+		for _, id := range ids {
+			if contains(artifactIDs(dist), id) {
+				return dist.ID
+			}
+		}
+		if image != "" && image == imageName(dist) {
+			return dist.ID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,166 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// SupervisorModule is the Go module of the OpAMP supervisor.
+const SupervisorModule = "github.com/open-telemetry/opentelemetry-collector-contrib/cmd/opampsupervisor"
+
+// SupervisorID is the ID of the build of the distribution's OpAMP
+// supervisor.
+func (d Distribution) SupervisorID() string {
+	return d.ID + "-supervisor"
+}
+
+// SupervisorBinaryName is the name of the OpAMP supervisor binary, named
+// after the collector's so that the packages of several distributions can be
+// installed side by side.
+func (d Distribution) SupervisorBinaryName() string {
+	return d.BinaryName() + "-supervisor"
+}
+
+// SupervisorVersion is the version of the OpAMP supervisor bundled with the
+// distribution, the one released with the collector.
+func (d Distribution) SupervisorVersion() string {
+	return "v" + strings.TrimPrefix(d.Dist.Version, "v")
+}
+
+// supervisorSourceDir holds the sources of the OpAMP supervisor, relative to
+// the repository.
+func supervisorSourceDir(dist Distribution) string {
+	return path.Join("distributions", dist.ID, "_supervisor")
+}
+
+// supervisorImageDockerfile builds the image running the OpAMP supervisor,
+// relative to the repository.
+func supervisorImageDockerfile(dist Distribution) string {
+	return path.Join("distributions", dist.ID, "_supervisor-image", "Dockerfile")
+}
+
+// supervisorImageConfig is the supervisor configuration of the supervisor
+// image, such as configs/otelcol-contrib-supervisor.yaml.
+func supervisorImageConfig(dist Distribution) string {
+	return path.Join("configs", fmt.Sprintf("%s-supervisor.yaml", dist.ID))
+}
+
+// SupervisorBuild configures the goreleaser build of the OpAMP supervisor
+// bundled with a distribution, for the same platforms as the collector. The
+// distribution's hooks check the collector only.
+func SupervisorBuild(dist Distribution) config.Build {
+	b := Build(dist)
+	b.ID = dist.SupervisorID()
+	b.Dir = supervisorSourceDir(dist)
+	b.Binary = dist.SupervisorBinaryName()
+	b.Hooks = config.BuildHookConfig{}
+	return b
+}
+
+// WriteSupervisors prepares the OpAMP supervisor of the distributions
+// bundling it: its sources are copied from the module cache to
+// distributions/<dist>/_supervisor, and the Dockerfile of the supervisor
+// image, which copies it into the distribution's image and runs it, is
+// written to distributions/<dist>/_supervisor-image.
+func WriteSupervisors(dists []Distribution) error {
+	_, err := forEach(dists, func(dist Distribution) (struct{}, error) {
+		if !dist.Release.OpAMPSupervisor {
+			return struct{}{}, nil
+		}
+		if err := writeSupervisorSources(dist); err != nil {
+			return struct{}{}, fmt.Errorf("failed to prepare the OpAMP supervisor of distribution %q: %w", dist.ID, err)
+		}
+		if err := writeSupervisorDockerfile(dist); err != nil {
+			return struct{}{}, fmt.Errorf("failed to write the supervisor Dockerfile of distribution %q: %w", dist.ID, err)
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+func writeSupervisorSources(dist Distribution) error {
+	module := fmt.Sprintf("%s@%s", SupervisorModule, dist.SupervisorVersion())
+	// go mod download reports its errors in its JSON output.
+	out, _ := exec.Command("go", "mod", "download", "-json", module).Output()
+	var download struct {
+		Dir   string
+		Error string
+	}
+	if err := json.Unmarshal(out, &download); err != nil {
+		return fmt.Errorf("failed to download %s: %w", module, err)
+	}
+	if download.Error != "" {
+		return fmt.Errorf("failed to download %s: %s", module, download.Error)
+	}
+
+	dst := supervisorSourceDir(dist)
+	if err := os.RemoveAll(dst); err != nil {
+		return err
+	}
+	// The module cache is read-only, so the files are copied rather than the
+	// permissions.
+	return filepath.WalkDir(download.Dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(download.Dir, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, b, 0o644)
+	})
+}
+
+func writeSupervisorDockerfile(dist Distribution) error {
+	dockerfile, err := os.ReadFile(dist.ImageDockerfile())
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.Write(dockerfile)
+	if !bytes.HasSuffix(dockerfile, []byte("\n")) {
+		buf.WriteString("\n")
+	}
+	binary := "/" + dist.SupervisorBinaryName()
+	configPath := path.Join("/etc", dist.ID, "supervisor.yaml")
+	fmt.Fprintf(&buf, "COPY --chmod=755 %s %s\n", dist.SupervisorBinaryName(), binary)
+	fmt.Fprintf(&buf, "COPY %s %s\n", supervisorImageConfig(dist), configPath)
+	fmt.Fprintf(&buf, "ENTRYPOINT [%q]\n", binary)
+	fmt.Fprintf(&buf, "CMD [\"--config\", %q]\n", configPath)
+
+	if err := os.MkdirAll(path.Dir(supervisorImageDockerfile(dist)), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(supervisorImageDockerfile(dist), buf.Bytes(), 0o644)
+}
@@ -0,0 +1,178 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// VerifyToolchainHook is the goreleaser before hook failing the release when
+// a distribution would be built with another Go toolchain than the pinned
+// one.
+func VerifyToolchainHook(dists []Distribution) string {
+	ids := make([]string, 0, len(dists))
+	for _, dist := range dists {
+		ids = append(ids, dist.ID)
+	}
+	return "go run ./cmd/goreleaser verify-toolchain -d " + strings.Join(ids, ",")
+}
+
+// ModToolchain returns the Go version of the toolchain directive of the
+// distribution's generated go.mod, without the "go" prefix, or an empty
+// string when the sources or the directive are missing.
+func ModToolchain(dist Distribution) (string, error) {
+	return modDirective(dist, "toolchain")
+}
+
+// ModGoVersion returns the minimum Go version of the go directive of the
+// distribution's generated go.mod, or an empty string when the sources or
+// the directive are missing.
+func ModGoVersion(dist Distribution) (string, error) {
+	return modDirective(dist, "go")
+}
+
+// modDirective returns the Go version of the directive of the distribution's
+// generated go.mod, without the "go" prefix.
+func modDirective(dist Distribution, directive string) (string, error) {
+	b, err := os.ReadFile(path.Join("distributions", dist.ID, "_build", "go.mod"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == directive {
+			return strings.TrimPrefix(fields[1], "go"), nil
+		}
+	}
+	return "", nil
+}
+
+// PinnedGoVersion returns the Go version the distribution must be built
+// with: its go_version, or the toolchain directive of its go.mod, or an
+// empty string when it pins neither.
+func PinnedGoVersion(dist Distribution) (string, error) {
+	if goVersion := dist.GoVersion(); goVersion != "" {
+		return goVersion, nil
+	}
+	return ModToolchain(dist)
+}
+
+// CheckToolchainPins fails when the go_version of a distribution and the
+// toolchain directive of its go.mod disagree, as only the former would be
+// used.
+func CheckToolchainPins(dists []Distribution) error {
+	for _, dist := range dists {
+		toolchain, err := ModToolchain(dist)
+		if err != nil {
+			return fmt.Errorf("failed to read the go.mod of distribution %q: %w", dist.ID, err)
+		}
+		if goVersion := dist.GoVersion(); goVersion != "" && toolchain != "" && goVersion != toolchain {
+			return fmt.Errorf("distribution %q pins go_version %s but its go.mod pins toolchain go%s", dist.ID, goVersion, toolchain)
+		}
+	}
+	return nil
+}
+
+// VerifyToolchains fails unless the Go toolchain building each distribution,
+// as selected by the go command with the builds' environment, is the pinned
+// one or, for distributions pinning none, is at least the go directive of
+// their go.mod. Distributions whose sources aren't generated are skipped.
+func VerifyToolchains(dists []Distribution) error {
+	if err := CheckToolchainPins(dists); err != nil {
+		return err
+	}
+	_, err := forEach(dists, func(dist Distribution) (struct{}, error) {
+		pinned, err := PinnedGoVersion(dist)
+		if err != nil {
+			return struct{}{}, fmt.Errorf("failed to read the go.mod of distribution %q: %w", dist.ID, err)
+		}
+		minimum, err := ModGoVersion(dist)
+		if err != nil {
+			return struct{}{}, fmt.Errorf("failed to read the go.mod of distribution %q: %w", dist.ID, err)
+		}
+		if pinned == "" && minimum == "" {
+			return struct{}{}, nil
+		}
+		version, err := buildGoVersion(dist)
+		if err != nil {
+			return struct{}{}, fmt.Errorf("failed to find the Go toolchain of distribution %q: %w", dist.ID, err)
+		}
+		if pinned == "" {
+			if goSemver(version) != "" && semver.Compare(goSemver(version), goSemver(minimum)) < 0 {
+				return struct{}{}, fmt.Errorf("distribution %q requires go%s but would be built with %s", dist.ID, minimum, version)
+			}
+			return struct{}{}, nil
+		}
+		if version != "go"+pinned {
+			return struct{}{}, fmt.Errorf("distribution %q pins go%s but would be built with %s", dist.ID, pinned, version)
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// buildGoVersion is the version of the Go toolchain the go command selects
+// for the distribution's builds, from the directory of its sources, if
+// generated, and with their GOTOOLCHAIN.
+func buildGoVersion(dist Distribution) (string, error) {
+	cmd := exec.Command("go", "env", "GOVERSION")
+	if dir := path.Join("distributions", dist.ID, "_build"); isDir(dir) {
+		cmd.Dir = dir
+	}
+	cmd.Env = append(os.Environ(), buildEnv(dist, false)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// goSemver converts a Go version, such as "go1.21.4", "1.21" or "1.22rc1",
+// to semver, like "v1.21.4", "v1.21.0" or "v1.22.0-rc1". Development
+// toolchains, with no version, are converted to an empty string.
+func goSemver(v string) string {
+	v = strings.TrimPrefix(v, "go")
+	i := strings.IndexFunc(v, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	core, pre := v, ""
+	if i >= 0 {
+		core, pre = v[:i], "-"+v[i:]
+	}
+	for strings.Count(core, ".") < 2 {
+		core += ".0"
+	}
+	if v := "v" + core + pre; semver.IsValid(v) {
+		return v
+	}
+	return ""
+}
+
+func isDir(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+)
+
+const (
+	// UBIBaseImage is the Red Hat Universal Base Image of the UBI images,
+	// which provides the CA certificates and glibc.
+	UBIBaseImage = "registry.access.redhat.com/ubi9/ubi-minimal:9.3"
+	// UBIDockerfile is the name of the Dockerfile of the UBI images.
+	UBIDockerfile = "Dockerfile.ubi"
+)
+
+// UBIArchitectures are the architectures UBI images are published for.
+var UBIArchitectures = []string{"amd64", "arm64", "ppc64le", "s390x"}
+
+//go:embed templates/Dockerfile.ubi.tmpl
+var ubiDockerfileTemplate string
+
+var ubiDockerfileTmpl = template.Must(template.New("Dockerfile.ubi").
+	Funcs(template.FuncMap{"join": strings.Join}).
+	Parse(ubiDockerfileTemplate))
+
+// ubiImageDockerfile is the path of the Dockerfile of the distribution's UBI
+// images.
+func ubiImageDockerfile(dist Distribution) string {
+	return path.Join("distributions", dist.ID, UBIDockerfile)
+}
+
+// UBIImageDockerfile renders the Dockerfile of the UBI images of a
+// distribution, copying the binary onto the UBI minimal image.
+func UBIImageDockerfile(dist Distribution) ([]byte, error) {
+	var buf bytes.Buffer
+	err := ubiDockerfileTmpl.Execute(&buf, struct {
+		ID        string
+		Binary    string
+		BaseImage string
+		Ports     []string
+		UID       int
+		GID       int
+		Configs   []bundledConfig
+	}{
+		ID:        dist.ID,
+		Binary:    dist.BinaryName(),
+		BaseImage: UBIBaseImage,
+		Ports:     dist.Ports(),
+		UID:       dist.UID(),
+		GID:       dist.GID(),
+		Configs:   bundledConfigs(dist),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the UBI Dockerfile for distribution %q: %w", dist.ID, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ubiImageVariant is the UBI image of the distribution, tagged such as
+// 0.89.0-ubi.
+func ubiImageVariant(dist Distribution) ImageVariant {
+	return ImageVariant{
+		Suffix:     "ubi",
+		BuildID:    dist.ID,
+		Files:      []string{path.Join("configs", fmt.Sprintf("%s.yaml", dist.ID))},
+		Dockerfile: ubiImageDockerfile(dist),
+		Archs:      UBIArchitectures,
+		BaseImage:  UBIBaseImage,
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// componentSections are the sections of an ocb manifest listing components.
+var componentSections = []string{"receivers", "exporters", "extensions", "processors", "connectors"}
+
+// WriteVariantManifests writes the ocb manifest of each build variant of the
+// given distributions to distributions/<dist>/_build-<variant>/manifest.yaml,
+// and returns the paths of those manifests relative to the distribution's
+// directory.
+func WriteVariantManifests(dists []Distribution) ([]string, error) {
+	manifests, err := forEach(dists, writeVariantManifests)
+	if err != nil {
+		return nil, err
+	}
+	var r []string
+	for _, m := range manifests {
+		r = append(r, m...)
+	}
+	return r, nil
+}
+
+func writeVariantManifests(dist Distribution) ([]string, error) {
+	manifest := path.Join("distributions", dist.ID, "manifest.yaml")
+	b, err := os.ReadFile(manifest)
+	if err != nil {
+		return nil, err
+	}
+	var r []string
+	for _, variant := range dist.Release.BuildVariants {
+		out, err := variantManifest(b, dist, variant)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate the manifest of variant %q of distribution %q: %w", variant.Name, dist.ID, err)
+		}
+		dir := path.Join("distributions", dist.ID, VariantBuildDir(variant))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path.Join(dir, "manifest.yaml"), out, 0o644); err != nil {
+			return nil, err
+		}
+		r = append(r, path.Join(VariantBuildDir(variant), "manifest.yaml"))
+	}
+	return r, nil
+}
+
+// variantManifest derives the ocb manifest of a build variant from the
+// distribution's manifest: the binary and output path are renamed, and the
+// excluded components are removed.
+func variantManifest(manifest []byte, dist Distribution, variant BuildVariant) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(manifest, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("manifest is not a YAML mapping")
+	}
+	root := doc.Content[0]
+
+	distNode := mappingValue(root, "dist")
+	if distNode == nil {
+		return nil, fmt.Errorf("manifest has no dist section")
+	}
+	setMappingValue(distNode, "name", dist.VariantBinaryName(variant))
+	setMappingValue(distNode, "output_path", "./"+VariantBuildDir(variant))
+
+	excluded := map[string]bool{}
+	for _, module := range variant.ExcludeComponents {
+		excluded[module] = true
+	}
+	for _, section := range componentSections {
+		components := mappingValue(root, section)
+		if components == nil || components.Kind != yaml.SequenceNode {
+			continue
+		}
+		var kept []*yaml.Node
+		for _, component := range components.Content {
+			if gomod := mappingValue(component, "gomod"); gomod != nil {
+				if fields := strings.Fields(gomod.Value); len(fields) > 0 && excluded[fields[0]] {
+					continue
+				}
+			}
+			kept = append(kept, component)
+		}
+		components.Content = kept
+	}
+
+	return yaml.Marshal(&doc)
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func setMappingValue(node *yaml.Node, key, value string) {
+	if v := mappingValue(node, key); v != nil {
+		v.SetString(value)
+		return
+	}
+	k := &yaml.Node{}
+	k.SetString(key)
+	v := &yaml.Node{}
+	v.SetString(value)
+	node.Content = append(node.Content, k, v)
+}
@@ -0,0 +1,63 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// Offline generates the air-gapped profile of the configuration: the builds
+// compile the modules vendored by WriteVendoredModules, with the module proxy
+// turned off, and the images are built from the base images already
+// available, without pulling them.
+var Offline bool
+
+// WriteVendoredModules vendors the modules of the generated sources of the
+// distributions, of their build variants and of their OpAMP supervisor, for
+// the offline profile. It must run after the sources are generated, while
+// the module proxy is still reachable.
+func WriteVendoredModules(dists []Distribution) error {
+	_, err := forEach(dists, func(dist Distribution) (struct{}, error) {
+		dirs := []string{path.Join("distributions", dist.ID, "_build")}
+		for _, variant := range dist.Release.BuildVariants {
+			dirs = append(dirs, path.Join("distributions", dist.ID, VariantBuildDir(variant)))
+		}
+		if dist.Release.OpAMPSupervisor {
+			dirs = append(dirs, supervisorSourceDir(dist))
+		}
+		for _, dir := range dirs {
+			if err := vendorModules(dir); err != nil {
+				return struct{}{}, fmt.Errorf("failed to vendor the modules of distribution %q in %s: %w", dist.ID, dir, err)
+			}
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+func vendorModules(dir string) error {
+	cmd := exec.Command("go", "mod", "vendor")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// ComponentProblem is a component module@version failing the verification
+// against the Go module proxy and checksum database.
+type ComponentProblem struct {
+	Distribution string
+	Module       string
+	Version      string
+	// Problem is "missing", "retracted" or "mismatched".
+	Problem string
+	Detail  string
+}
+
+// moduleInfo is the part of the output of `go list -m -json` and `go mod
+// download -json` describing a module version.
+type moduleInfo struct {
+	Path      string
+	Version   string
+	Retracted []string
+	// Error is a string for go mod download, and an object for go list.
+	Error json.RawMessage
+}
+
+// err returns the error resolving the module, if any.
+func (m moduleInfo) err() string {
+	if len(m.Error) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(m.Error, &s); err == nil {
+		return s
+	}
+	var e struct {
+		Err string
+	}
+	if err := json.Unmarshal(m.Error, &e); err == nil {
+		return e.Err
+	}
+	return string(m.Error)
+}
+
+// VerifyComponents resolves the module of every component of the given
+// distributions through the Go module proxy, checking it against the
+// checksum database, and reports the missing, retracted or mismatched
+// versions.
+func VerifyComponents(dists []Distribution) ([]ComponentProblem, error) {
+	results, err := forEach(dists, verifyDistributionComponents)
+	if err != nil {
+		return nil, err
+	}
+	var r []ComponentProblem
+	for _, problems := range results {
+		r = append(r, problems...)
+	}
+	return r, nil
+}
+
+func verifyDistributionComponents(dist Distribution) ([]ComponentProblem, error) {
+	components, err := manifestComponents(dist)
+	if err != nil {
+		return nil, err
+	}
+
+	var r []ComponentProblem
+	failed := map[string]bool{}
+	downloads, err := downloadModules(components)
+	if err != nil {
+		return nil, err
+	}
+	for _, module := range downloads {
+		detail := module.err()
+		if detail == "" {
+			continue
+		}
+		problem := "missing"
+		if strings.Contains(detail, "checksum mismatch") || strings.Contains(detail, "SECURITY ERROR") {
+			problem = "mismatched"
+		}
+		failed[module.Path] = true
+		r = append(r, ComponentProblem{
+			Distribution: dist.ID,
+			Module:       module.Path,
+			Version:      module.Version,
+			Problem:      problem,
+			Detail:       detail,
+		})
+	}
+
+	modules, err := listModules(components)
+	if err != nil {
+		return nil, err
+	}
+	for _, module := range modules {
+		if len(module.Retracted) == 0 || failed[module.Path] {
+			continue
+		}
+		r = append(r, ComponentProblem{
+			Distribution: dist.ID,
+			Module:       module.Path,
+			Version:      module.Version,
+			Problem:      "retracted",
+			Detail:       strings.Join(module.Retracted, "; "),
+		})
+	}
+	return r, nil
+}
+
+// downloadModules downloads the modules of the components, which verifies
+// them against the checksum database.
+func downloadModules(components []Component) ([]moduleInfo, error) {
+	return goModules(components, "mod", "download", "-json")
+}
+
+// listModules lists the modules of the components, along with their
+// retractions.
+func listModules(components []Component) ([]moduleInfo, error) {
+	return goModules(components, "list", "-m", "-e", "-json", "-retracted")
+}
+
+// goModules runs a go command reporting the modules of the components as a
+// stream of JSON objects, each holding its own error.
+func goModules(components []Component, args ...string) ([]moduleInfo, error) {
+	if len(components) == 0 {
+		return nil, nil
+	}
+	for _, c := range components {
+		args = append(args, fmt.Sprintf("%s@%s", c.Module, c.Version))
+	}
+	out, err := exec.Command("go", args...).Output()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, fmt.Errorf("failed to run go %s: %w", args[0], err)
+	}
+
+	var r []moduleInfo
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var module moduleInfo
+		if err := dec.Decode(&module); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse the output of go %s: %w", args[0], err)
+		}
+		r = append(r, module)
+	}
+	if len(r) == 0 && exitErr != nil {
+		return nil, fmt.Errorf("go %s failed: %w\n%s", args[0], err, exitErr.Stderr)
+	}
+	return r, nil
+}
@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// TagSigners are the maintainers allowed to sign release tags.
+type TagSigners struct {
+	// GPGKeyring is a file holding the armored GPG public keys of the
+	// maintainers.
+	GPGKeyring string
+	// SSHAllowedSigners is a file listing the SSH public keys of the
+	// maintainers, in the ssh-keygen ALLOWED SIGNERS format.
+	SSHAllowedSigners string
+}
+
+// VerifyTag checks that the given tag is signed, with either GPG or SSH, by
+// one of the allowed signers. GPG signatures are verified against a keyring
+// holding only the allowed keys, so any other key is rejected. It fails when
+// a signers file is missing, or when they list no signer at all.
+func VerifyTag(tag string, signers TagSigners) error {
+	listed := false
+	for _, file := range []string{signers.GPGKeyring, signers.SSHAllowedSigners} {
+		if file == "" {
+			continue
+		}
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read the allowed signers: %w", err)
+		}
+		listed = listed || listsSigners(b)
+	}
+	if !listed {
+		return errors.New("no allowed signers listed, refusing to trust any tag")
+	}
+
+	gnupgHome, err := os.MkdirTemp("", "otelcol-verify-tag")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(gnupgHome)
+
+	env := append(os.Environ(), fmt.Sprintf("GNUPGHOME=%s", gnupgHome))
+	if signers.GPGKeyring != "" {
+		cmd := exec.Command("gpg", "--batch", "--import", signers.GPGKeyring)
+		cmd.Env = env
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to import the allowed GPG keys: %w\n%s", err, out)
+		}
+	}
+
+	args := []string{}
+	if signers.SSHAllowedSigners != "" {
+		args = append(args, "-c", fmt.Sprintf("gpg.ssh.allowedSignersFile=%s", signers.SSHAllowedSigners))
+	}
+	args = append(args, "verify-tag", "--verbose", tag)
+
+	cmd := exec.Command("git", args...)
+	cmd.Env = env
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("tag %s is not signed by an allowed maintainer: %w\n%s", tag, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// listsSigners reports whether a signers file holds anything but comments
+// and blank lines.
+func listsSigners(b []byte) bool {
+	for _, line := range strings.Split(string(b), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			return true
+		}
+	}
+	return false
+}
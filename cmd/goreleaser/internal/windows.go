@@ -0,0 +1,235 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// WindowsDockerfile is the name of the Dockerfile of the Windows images.
+const WindowsDockerfile = "Dockerfile.windows"
+
+var (
+	// WindowsVersions are the Windows Server releases the Windows images can
+	// be based on. A Windows container only runs on a host of its release.
+	WindowsVersions = []string{"ltsc2019", "ltsc2022"}
+
+	// WindowsBaseImages are the Windows base images, by name. nanoserver is
+	// the default, servercore provides the full Windows API.
+	WindowsBaseImages = map[string]string{
+		"nanoserver": "mcr.microsoft.com/windows/nanoserver",
+		"servercore": "mcr.microsoft.com/windows/servercore",
+	}
+)
+
+//go:embed templates/Dockerfile.windows.tmpl
+var windowsDockerfileTemplate string
+
+var windowsDockerfileTmpl = template.Must(template.New("Dockerfile.windows").
+	Funcs(template.FuncMap{"join": strings.Join}).
+	Parse(windowsDockerfileTemplate))
+
+// windowsBaseImage is the repository of the distribution's Windows base
+// image.
+func windowsBaseImage(dist Distribution) string {
+	if base := dist.Release.Docker.WindowsBaseImage; base != "" {
+		return WindowsBaseImages[base]
+	}
+	return WindowsBaseImages["nanoserver"]
+}
+
+// windowsImageDockerfile is the path of the Dockerfile of the distribution's
+// Windows images.
+func windowsImageDockerfile(dist Distribution) string {
+	return path.Join("distributions", dist.ID, WindowsDockerfile)
+}
+
+// WindowsImageDockerfile renders the Dockerfile of the Windows images of a
+// distribution, copying the windows/amd64 binary onto the base image of the
+// BASE_IMAGE build argument.
+func WindowsImageDockerfile(dist Distribution) ([]byte, error) {
+	var buf bytes.Buffer
+	err := windowsDockerfileTmpl.Execute(&buf, struct {
+		ID        string
+		Binary    string
+		BaseImage string
+		Ports     []string
+		Configs   []bundledConfig
+	}{
+		ID:        dist.ID,
+		Binary:    dist.BinaryName() + ".exe",
+		BaseImage: fmt.Sprintf("%s:%s", windowsBaseImage(dist), WindowsVersions[len(WindowsVersions)-1]),
+		Ports:     dist.Ports(),
+		Configs:   bundledConfigs(dist),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the Windows Dockerfile for distribution %q: %w", dist.ID, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// windowsImageVariant is the Windows image of the distribution for a Windows
+// Server release, tagged such as 0.89.0-windows-ltsc2022-amd64.
+func windowsImageVariant(dist Distribution, version string) ImageVariant {
+	return ImageVariant{
+		Suffix:     "windows-" + version,
+		BuildID:    dist.ID,
+		BuildArgs:  []string{fmt.Sprintf("--build-arg=BASE_IMAGE=%s:%s", windowsBaseImage(dist), version)},
+		Files:      withBundledConfigs(dist, []string{path.Join("configs", fmt.Sprintf("%s.yaml", dist.ID))}),
+		Dockerfile: windowsImageDockerfile(dist),
+		BaseImage:  fmt.Sprintf("%s:%s", windowsBaseImage(dist), version),
+	}
+}
+
+// WindowsImage is a Windows image of a distribution. goreleaser can't build
+// them, as the docker daemon of a linux host can't load Windows images, so
+// they are built by docker on a Windows host of their Windows Server release
+// from the windows/amd64 binary of the release, and pushed before goreleaser
+// publishes the multi-arch manifests listing them.
+type WindowsImage struct {
+	Dist       Distribution
+	Dockerfile string
+	// Files are the files of the build context besides the binary.
+	Files      []string
+	BuildFlags []string
+	Tags       []string
+}
+
+// WindowsImages are the Windows images of the distributions opting in to the
+// given Windows Server release, with the same tags, labels and build
+// arguments as if goreleaser built them for the commit.
+func WindowsImages(imagePrefixes []string, dists []Distribution, version string, commit ReleaseCommit) ([]WindowsImage, error) {
+	if !contains(WindowsVersions, version) {
+		return nil, fmt.Errorf("unsupported Windows Server release %q, expected one of %s", version, strings.Join(WindowsVersions, ", "))
+	}
+	out, err := exec.Command("git", "remote", "get-url", "origin").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the git URL: %w", err)
+	}
+	date, err := time.Parse(time.RFC3339, commit.CommitDate)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]string{
+		"Version":         commit.Version,
+		"FullCommit":      commit.FullCommit,
+		"CommitDate":      commit.CommitDate,
+		"CommitTimestamp": fmt.Sprint(date.Unix()),
+		"ProjectName":     ProjectName,
+		"GitURL":          strings.TrimSpace(string(out)),
+	}
+	render := func(templates []string) (r []string, err error) {
+		for _, t := range templates {
+			var buf bytes.Buffer
+			tmpl, err := template.New("flag").Option("missingkey=error").Parse(t)
+			if err != nil {
+				return nil, err
+			}
+			if err := tmpl.Execute(&buf, data); err != nil {
+				return nil, err
+			}
+			r = append(r, buf.String())
+		}
+		return
+	}
+
+	var r []WindowsImage
+	for _, dist := range dists {
+		if !contains(dist.Release.Docker.Windows, version) {
+			continue
+		}
+		variant := windowsImageVariant(dist, version)
+		image := DockerImage(imagePrefixes, dist, variant, "amd64", "")
+		var flags []string
+		for _, flag := range image.BuildFlagTemplates {
+			// docker builds the images of the platform of the Windows host.
+			if flag != "--platform=linux/amd64" {
+				flags = append(flags, flag)
+			}
+		}
+		buildFlags, err := render(flags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render the build flags of the Windows images of distribution %q: %w", dist.ID, err)
+		}
+		tags, err := render(image.ImageTemplates)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render the tags of the Windows images of distribution %q: %w", dist.ID, err)
+		}
+		r = append(r, WindowsImage{
+			Dist:       dist,
+			Dockerfile: image.Dockerfile,
+			Files:      image.Files,
+			BuildFlags: buildFlags,
+			Tags:       tags,
+		})
+	}
+	return r, nil
+}
+
+// WindowsBinary is the path of the windows/amd64 binary of the distribution
+// in goreleaser's output directory, listed in the artifacts.json of the
+// directory or of one of its split builds.
+func WindowsBinary(distDir string, dist Distribution) (string, error) {
+	files, err := filepath.Glob(filepath.Join(distDir, "*", "artifacts.json"))
+	if err != nil {
+		return "", err
+	}
+	for _, file := range append([]string{filepath.Join(distDir, "artifacts.json")}, files...) {
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			continue
+		}
+		var artifacts []releaseArtifact
+		if err := readJSON(file, &artifacts); err != nil {
+			return "", err
+		}
+		for _, a := range artifacts {
+			if a.Type == "Binary" && a.Extra.ID == dist.ID && a.Goos == "windows" && a.Goarch == "amd64" && (a.Goamd64 == "" || a.Goamd64 == "v1") {
+				return a.Path, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no windows/amd64 binary of distribution %q in %s", dist.ID, distDir)
+}
+
+// windowsPaths converts the Windows paths of an ENTRYPOINT or CMD
+// instruction, such as C:\etc\otelcol\config.yaml, to the paths of the COPY
+// instructions, such as /etc/otelcol/config.yaml.
+func windowsPaths(args []string) (r []string) {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, `C:\`) {
+			arg = strings.ReplaceAll(strings.TrimPrefix(arg, "C:"), `\`, "/")
+		}
+		r = append(r, arg)
+	}
+	return
+}
+
+// windowsImageTemplates are the Windows images of the given version listed in
+// the distribution's multi-arch manifests, next to the linux ones.
+func windowsImageTemplates(prefix, version string, dist Distribution) (r []string) {
+	for _, windowsVersion := range dist.Release.Docker.Windows {
+		r = append(r, fmt.Sprintf("%s/%s:%s-windows-%s-amd64", prefix, imageName(dist), version, windowsVersion))
+	}
+	return
+}
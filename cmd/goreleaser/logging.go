@@ -0,0 +1,43 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/open-telemetry/opentelemetry-collector-releases/cmd/goreleaser/internal"
+)
+
+// logger is replaced once the logging flags are parsed.
+var logger, _ = internal.NewLogger(os.Stderr, internal.LevelInfo, "text")
+
+// logFlags adds the logging flags to fs, and returns the function setting up
+// the logger once fs is parsed.
+func logFlags(fs *flag.FlagSet) func() {
+	level := fs.String("log-level", "info", "Minimum level of the logs: debug, info, warn or error")
+	format := fs.String("log-format", "text", "Format of the logs: text or json")
+	return func() {
+		l, err := internal.ParseLevel(*level)
+		if err != nil {
+			logger.Fatal("invalid -log-level", "error", err)
+		}
+		lg, err := internal.NewLogger(os.Stderr, l, *format)
+		if err != nil {
+			logger.Fatal("invalid -log-format", "error", err)
+		}
+		logger = lg
+	}
+}
@@ -15,9 +15,14 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"flag"
-	"log"
+	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -25,19 +30,312 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-releases/cmd/goreleaser/internal"
 )
 
-var distsFlag = flag.String("d", "", "Collector distributions(s) to build, comma-separated")
+var (
+	distsFlag       = flag.String("d", "", "Collector distributions(s) to build, comma-separated")
+	outputFlag      = flag.String("o", "", "Write the goreleaser configuration to this file instead of stdout")
+	summaryFlag     = flag.String("summary", "", "Write a JSON summary of the sections of the goreleaser configuration changed by this run to this file, requires -o")
+	checkFlag       = flag.Bool("check", false, "Check that the -o file is up to date instead of writing it")
+	dockerfilesFlag = flag.Bool("dockerfiles", false, "Render the Dockerfiles of distributions declaring OS packages instead of the goreleaser configuration")
+	embedConfigFlag = flag.Bool("embed-config", false, "Embed the default config into the generated sources of distributions opting in, instead of generating the goreleaser configuration")
+	variantsFlag    = flag.Bool("variant-manifests", false, "Write the ocb manifests of the build variants and print their paths, instead of generating the goreleaser configuration")
+	assetsFlag      = flag.Bool("image-assets", false, "Download the assets of the image asset variants and write their Dockerfiles, instead of generating the goreleaser configuration")
+	stampFlag       = flag.Bool("stamp-version", false, "Make the generated sources report the version set by the release builds, instead of generating the goreleaser configuration")
+	vendorFlag      = flag.Bool("vendor", false, "Vendor the modules of the generated sources for the offline profile, instead of generating the goreleaser configuration")
+	offlineFlag     = flag.Bool("offline", false, "Generate the air-gapped profile, building the vendored modules without a module proxy and the images without pulling their base images")
+	supervisorsFlag = flag.Bool("supervisors", false, "Prepare the sources and image of the OpAMP supervisor of distributions bundling it, instead of generating the goreleaser configuration")
+	platformsFlag   = flag.Bool("platforms", false, "Print the supported-platform matrix as JSON, instead of the goreleaser configuration")
+	splitFlag       = flag.Bool("split-matrix", false, "Print the GitHub Actions matrix of the targets the release is split by, as JSON, instead of the goreleaser configuration")
+	inventoryFlag   = flag.Bool("inventory", false, "Write the component inventory of each distribution, instead of generating the goreleaser configuration")
+	auditFlag       = flag.Bool("audit", false, "Check the component versions pinned by the manifests, instead of generating the goreleaser configuration")
+	projectNameFlag = flag.String("project-name", internal.ProjectName, "Name of the goreleaser project, used in the names of the release assets")
+	checksumFlag    = flag.String("checksum-name", internal.ChecksumNameTemplate, "Name template of the checksums file")
+	prefixesFlag    = flag.String("image-prefixes", "", imagePrefixesUsage)
+	ecrPublicFlag   = flag.String("ecr-public-alias", "", "Also publish the images to Amazon ECR Public, under this registry alias")
+	skipLatestFlag  = flag.Bool("skip-latest", false, "Leave the latest image tags of every distribution out")
+	distDirFlag     = flag.String("dist", "", "goreleaser's output directory, such as a scratch volume (default: goreleaser's dist)")
+	workersFlag     = flag.Int("workers", internal.Workers, "Number of distributions processed concurrently")
+	projectsFlag    = flag.String("projects", "", "Generate the goreleaser projects defined in this file, instead of a single configuration for the -d distributions")
+)
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "sbom-diff":
+			sbomDiff(os.Args[2:])
+			return
+		case "dry-run":
+			dryRun(os.Args[2:])
+			return
+		case "verify-tag":
+			verifyTag(os.Args[2:])
+			return
+		case "doctor":
+			doctor(os.Args[2:])
+			return
+		case "verify-components":
+			verifyComponents(os.Args[2:])
+			return
+		case "verify-toolchain":
+			verifyToolchain(os.Args[2:])
+			return
+		case "deltas":
+			deltas(os.Args[2:])
+			return
+		case "update-feed":
+			updateFeed(os.Args[2:])
+			return
+		case "provenance-subjects":
+			provenanceSubjects(os.Args[2:])
+			return
+		case "image-digests":
+			imageDigests(os.Args[2:])
+			return
+		case "apko-images":
+			apkoImages(os.Args[2:])
+			return
+		case "previous-release":
+			previousRelease(os.Args[2:])
+			return
+		case "windows-images":
+			windowsImages(os.Args[2:])
+			return
+		case "prune-nightlies":
+			pruneNightlies(os.Args[2:])
+			return
+		}
+	}
+
+	setupLogging := logFlags(flag.CommandLine)
 	flag.Parse()
+	setupLogging()
+	internal.Workers = *workersFlag
+	internal.DistDir = *distDirFlag
+	internal.Offline = *offlineFlag
+	setImagePrefixes(*prefixesFlag)
+	if *ecrPublicFlag != "" {
+		internal.ImagePrefixes = append(internal.ImagePrefixes, path.Join(internal.ECRPublicRegistry, *ecrPublicFlag))
+	}
+	internal.ProjectName = *projectNameFlag
+	internal.ChecksumNameTemplate = *checksumFlag
+	internal.SkipLatest = *skipLatestFlag
+	releases, err := internal.ReleaseTags()
+	if err != nil {
+		logger.Fatal("failed to list the releases", "error", err)
+	}
+
+	if len(*projectsFlag) > 0 {
+		pf, err := internal.LoadProjectsFile(*projectsFlag)
+		if err != nil {
+			logger.Fatal("failed to load the projects", "file", *projectsFlag, "error", err)
+		}
+		if err := internal.WriteProjects(pf, internal.ImagePrefixes, releases); err != nil {
+			logger.Fatal("failed to generate the projects", "file", *projectsFlag, "error", err)
+		}
+		logger.Info("generated the goreleaser projects", "file", *projectsFlag, "projects", len(pf.Projects))
+		return
+	}
 
 	if len(*distsFlag) == 0 {
-		log.Fatal("no distributions to build")
+		logger.Fatal("no distributions to build")
+	}
+	dists, err := internal.LoadDistributions(strings.Split(*distsFlag, ","))
+	if err != nil {
+		logger.Fatal("failed to load the distributions", "error", err)
+	}
+	logger.Debug("loaded the distributions", "distributions", *distsFlag)
+
+	if *auditFlag {
+		if err := internal.AuditComponents(dists); err != nil {
+			logger.Fatal("the component audit failed", "error", err)
+		}
+		logger.Info("the component audit passed", "distributions", *distsFlag)
+		return
+	}
+
+	if *dockerfilesFlag {
+		if err := internal.WriteDockerfiles(dists); err != nil {
+			logger.Fatal("failed to render the Dockerfiles", "error", err)
+		}
+		for _, dist := range dists {
+			if dist.GeneratesDockerfile() {
+				logger.Info("rendered the Dockerfile", "distribution", dist.ID)
+			}
+		}
+		return
+	}
+
+	if *embedConfigFlag {
+		if err := internal.WriteEmbeddedConfigs(dists); err != nil {
+			logger.Fatal("failed to embed the default configs", "error", err)
+		}
+		for _, dist := range dists {
+			if dist.Release.EmbedConfig {
+				logger.Info("embedded the default config", "distribution", dist.ID)
+			}
+		}
+		return
+	}
+
+	if *variantsFlag {
+		manifests, err := internal.WriteVariantManifests(dists)
+		if err != nil {
+			logger.Fatal("failed to write the build variant manifests", "error", err)
+		}
+		for _, manifest := range manifests {
+			logger.Debug("wrote a build variant manifest", "manifest", manifest)
+			fmt.Println(manifest)
+		}
+		return
+	}
+
+	if *assetsFlag {
+		if err := internal.WriteImageAssets(dists); err != nil {
+			logger.Fatal("failed to prepare the image assets", "error", err)
+		}
+		return
+	}
+
+	if *stampFlag {
+		if err := internal.WriteVersionStamps(dists); err != nil {
+			logger.Fatal("failed to stamp the versions", "error", err)
+		}
+		logger.Info("stamped the versions", "distributions", *distsFlag)
+		return
+	}
+
+	if *supervisorsFlag {
+		if err := internal.WriteSupervisors(dists); err != nil {
+			logger.Fatal("failed to prepare the OpAMP supervisors", "error", err)
+		}
+		for _, dist := range dists {
+			if dist.Release.OpAMPSupervisor {
+				logger.Info("prepared the OpAMP supervisor", "distribution", dist.ID, "version", dist.SupervisorVersion())
+			}
+		}
+		return
+	}
+
+	if *vendorFlag {
+		if err := internal.WriteVendoredModules(dists); err != nil {
+			logger.Fatal("failed to vendor the modules", "error", err)
+		}
+		logger.Info("vendored the modules", "distributions", *distsFlag)
+		return
+	}
+
+	if *inventoryFlag {
+		if err := internal.WriteInventories(dists); err != nil {
+			logger.Fatal("failed to write the component inventories", "error", err)
+		}
+		logger.Info("wrote the component inventories", "directory", internal.InventoryDir)
+		return
+	}
+
+	if *platformsFlag {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(internal.Platforms(dists)); err != nil {
+			logger.Fatal("failed to write the platform matrix", "error", err)
+		}
+		return
+	}
+
+	if *splitFlag {
+		if err := json.NewEncoder(os.Stdout).Encode(internal.ReleaseSplitMatrix(dists)); err != nil {
+			logger.Fatal("failed to write the split matrix", "error", err)
+		}
+		return
+	}
+
+	if err := internal.ValidateDockerfiles(dists); err != nil {
+		logger.Fatal("the Dockerfiles don't match the generated configuration", "error", err)
+	}
+	if err := internal.CheckHealthChecks(dists); err != nil {
+		logger.Fatal("the configs don't enable the images' health checks", "error", err)
+	}
+	if err := internal.CheckToolchainPins(dists); err != nil {
+		logger.Fatal("the Go toolchain pins disagree", "error", err)
 	}
-	dists := strings.Split(*distsFlag, ",")
+	project := internal.Generate(internal.ImagePrefixes, dists, releases)
+	header, err := internal.Header(dists)
+	if err != nil {
+		logger.Fatal("failed to hash the manifests", "error", err)
+	}
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	if err := yaml.NewEncoder(&buf).Encode(&project); err != nil {
+		logger.Fatal("failed to encode the goreleaser configuration", "error", err)
+	}
+
+	if len(*outputFlag) == 0 {
+		if _, err := os.Stdout.Write(buf.Bytes()); err != nil {
+			logger.Fatal("failed to write the goreleaser configuration", "error", err)
+		}
+		return
+	}
+	if *checkFlag {
+		checkConfiguration(buf.Bytes(), dists)
+		return
+	}
+	writeConfiguration(buf.Bytes(), dists)
+}
+
+// imagePrefixesUsage documents the -image-prefixes flag of the commands
+// generating the images.
+var imagePrefixesUsage = "Registries and namespaces the images are published to, comma-separated, for forks publishing their own images (default: " + strings.Join(internal.ImagePrefixes, ",") + ")"
 
-	project := internal.Generate(internal.ImagePrefixes, dists)
+// setImagePrefixes replaces the image prefixes with the given comma-separated
+// ones, unless empty.
+func setImagePrefixes(prefixes string) {
+	if prefixes != "" {
+		internal.ImagePrefixes = strings.Split(prefixes, ",")
+	}
+}
+
+// checkConfiguration fails unless the -o file is the generated
+// configuration, telling whether it is stale or was edited by hand.
+func checkConfiguration(generated []byte, dists []internal.Distribution) {
+	current, err := os.ReadFile(*outputFlag)
+	if err != nil {
+		logger.Fatal("failed to read the goreleaser configuration", "output", *outputFlag, "error", err)
+	}
+	if bytes.Equal(current, generated) {
+		logger.Info("the goreleaser configuration is up to date", "output", *outputFlag)
+		return
+	}
+	if internal.HeaderManifestsHash(current) != internal.HeaderManifestsHash(generated) {
+		logger.Fatal("the goreleaser configuration is stale, the manifests changed since it was generated: run 'make generate-goreleaser'", "output", *outputFlag)
+	}
+	logger.Fatal("the goreleaser configuration differs from the generated one, it was edited by hand or the generator changed: run 'make generate-goreleaser'", "output", *outputFlag)
+}
+
+// writeConfiguration writes the goreleaser configuration to the -o file, and
+// reports which of its sections changed.
+func writeConfiguration(b []byte, dists []internal.Distribution) {
+	previous, err := os.ReadFile(*outputFlag)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logger.Fatal("failed to read the goreleaser configuration", "output", *outputFlag, "error", err)
+	}
+	if err := os.WriteFile(*outputFlag, b, 0o644); err != nil {
+		logger.Fatal("failed to write the goreleaser configuration", "output", *outputFlag, "error", err)
+	}
+
+	summary, err := internal.Summarize(*outputFlag, previous, b, dists)
+	if err != nil {
+		logger.Warn("failed to summarize the changes", "output", *outputFlag, "error", err)
+	}
+	logger.Info("generated the goreleaser configuration", "output", *outputFlag, "status", summary.Status)
+	for _, section := range summary.Sections {
+		logger.Info("section "+section.Reason, "section", section.Name, "distributions", strings.Join(section.Distributions, ","))
+	}
 
-	if err := yaml.NewEncoder(os.Stdout).Encode(&project); err != nil {
-		log.Fatal(err)
+	if len(*summaryFlag) > 0 {
+		b, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			logger.Fatal("failed to encode the summary", "error", err)
+		}
+		if err := os.WriteFile(*summaryFlag, append(b, '\n'), 0o644); err != nil {
+			logger.Fatal("failed to write the summary", "summary", *summaryFlag, "error", err)
+		}
 	}
 }
@@ -0,0 +1,44 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/open-telemetry/opentelemetry-collector-releases/cmd/goreleaser/internal"
+)
+
+// previousRelease prints the tag of the release preceding the given one in
+// its release line, which the release compares itself with, or nothing for
+// the first release.
+func previousRelease(args []string) {
+	fs := flag.NewFlagSet("previous-release", flag.ExitOnError)
+	tag := fs.String("tag", "", "Tag of the release, such as v0.89.0")
+	setupLogging := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLogging()
+
+	if len(*tag) == 0 {
+		logger.Fatal("no release tag")
+	}
+	tags, err := internal.ReleaseTags()
+	if err != nil {
+		logger.Fatal("failed to list the releases", "error", err)
+	}
+	if previous := internal.PreviousRelease(*tag, tags); previous != "" {
+		fmt.Println(previous)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-releases/cmd/goreleaser/internal"
+)
+
+// provenanceSubjects lists the artifacts of the release published by
+// goreleaser that the release workflow attests the SLSA provenance of.
+func provenanceSubjects(args []string) {
+	fs := flag.NewFlagSet("provenance-subjects", flag.ExitOnError)
+	dists := fs.String("d", "", "Collector distributions(s) to attest, comma-separated")
+	distDir := fs.String("dist", "dist", "goreleaser's dist directory, holding the published release")
+	setupLogging := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLogging()
+
+	if len(*dists) == 0 {
+		logger.Fatal("no distributions to attest")
+	}
+	distributions, err := internal.LoadDistributions(strings.Split(*dists, ","))
+	if err != nil {
+		logger.Fatal("failed to load the distributions", "error", err)
+	}
+	subjects, err := internal.BuildProvenanceSubjects(*distDir, distributions)
+	if err != nil {
+		logger.Fatal("failed to list the provenance subjects", "error", err)
+	}
+	if err := internal.WriteProvenanceSubjects(subjects); err != nil {
+		logger.Fatal("failed to write the provenance subjects", "error", err)
+	}
+	logger.Info("wrote the provenance subjects", "files", len(subjects.Files), "images", len(subjects.Images), "output", internal.ProvenanceDir)
+}
@@ -0,0 +1,52 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-releases/cmd/goreleaser/internal"
+)
+
+// pruneNightlies deletes the nightly and snapshot releases older than the
+// retention window, keeping the newest ones.
+func pruneNightlies(args []string) {
+	fs := flag.NewFlagSet("prune-nightlies", flag.ExitOnError)
+	repo := fs.String("repo", "open-telemetry/opentelemetry-collector-releases", "GitHub repository to prune")
+	keep := fs.Int("keep", 7, "Number of the newest nightly releases always kept")
+	retention := fs.Duration("retention", 30*24*time.Hour, "Nightly releases older than this are deleted")
+	dryRun := fs.Bool("dry-run", false, "Only list the releases that would be deleted")
+	setupLogging := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLogging()
+
+	releases, err := internal.ListNightlyReleases(*repo)
+	if err != nil {
+		logger.Fatal("failed to list the nightly releases", "error", err)
+	}
+	prune := internal.ReleasesToPrune(releases, *keep, *retention, time.Now())
+	for _, release := range prune {
+		if *dryRun {
+			logger.Info("would delete the release", "tag", release.TagName, "created", release.CreatedAt.Format(time.RFC3339))
+			continue
+		}
+		if err := internal.DeleteRelease(*repo, release.TagName); err != nil {
+			logger.Fatal("failed to prune the nightly releases", "error", err)
+		}
+		logger.Info("deleted the release", "tag", release.TagName, "created", release.CreatedAt.Format(time.RFC3339))
+	}
+	logger.Info("pruned the nightly releases", "nightlies", len(releases), "pruned", len(prune), "dry-run", *dryRun)
+}
@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-releases/cmd/goreleaser/internal"
+)
+
+// sbomDiff compares the SBOMs of two releases and writes a Markdown report
+// of the dependency changes of each distribution to stdout.
+func sbomDiff(args []string) {
+	fs := flag.NewFlagSet("sbom-diff", flag.ExitOnError)
+	dists := fs.String("d", "", "Collector distributions(s) to compare, comma-separated")
+	oldDir := fs.String("old", "", "Directory holding the SBOMs of the previous release")
+	newDir := fs.String("new", "", "Directory holding the SBOMs of the new release")
+	setupLogging := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLogging()
+
+	if len(*dists) == 0 {
+		logger.Fatal("no distributions to compare")
+	}
+	if len(*oldDir) == 0 || len(*newDir) == 0 {
+		logger.Fatal("both -old and -new SBOM directories are required")
+	}
+
+	diffs, err := internal.DiffSBOMs(*oldDir, *newDir, strings.Split(*dists, ","))
+	if err != nil {
+		logger.Fatal("failed to compare the SBOMs", "error", err)
+	}
+	if err := internal.WriteSBOMDiffReport(os.Stdout, diffs); err != nil {
+		logger.Fatal("failed to write the report", "error", err)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-releases/cmd/goreleaser/internal"
+)
+
+// updateFeed adds the release published by goreleaser to the update feed of
+// its channel.
+func updateFeed(args []string) {
+	fs := flag.NewFlagSet("update-feed", flag.ExitOnError)
+	dists := fs.String("d", "", "Collector distributions(s) to list in the feed, comma-separated")
+	distDir := fs.String("dist", "dist", "goreleaser's dist directory, holding the published release")
+	feedDir := fs.String("feed-dir", internal.UpdateFeedDir, "Directory of the update feed, holding the manifests of the previous releases")
+	repo := fs.String("repo", "open-telemetry/opentelemetry-collector-releases", "GitHub repository the release is published to")
+	setupLogging := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLogging()
+
+	if len(*dists) == 0 {
+		logger.Fatal("no distributions to list")
+	}
+	distributions, err := internal.LoadDistributions(strings.Split(*dists, ","))
+	if err != nil {
+		logger.Fatal("failed to load the distributions", "error", err)
+	}
+	feed, err := internal.BuildUpdateFeed(*distDir, *repo, distributions)
+	if err != nil {
+		logger.Fatal("failed to build the update feed", "error", err)
+	}
+	manifests, err := internal.WriteUpdateFeed(*feedDir, feed)
+	if err != nil {
+		logger.Fatal("failed to write the update feed", "error", err)
+	}
+	logger.Info("wrote the update feed", "channel", feed.Channel, "version", feed.Version, "manifests", strings.Join(manifests, ","))
+}
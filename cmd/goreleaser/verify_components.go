@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-releases/cmd/goreleaser/internal"
+)
+
+// verifyComponents checks that every component declared by the manifests
+// resolves through the Go module proxy, before a release is attempted.
+func verifyComponents(args []string) {
+	fs := flag.NewFlagSet("verify-components", flag.ExitOnError)
+	dists := fs.String("d", "", "Collector distributions(s) to verify, comma-separated")
+	setupLogging := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLogging()
+
+	if len(*dists) == 0 {
+		logger.Fatal("no distributions to verify")
+	}
+	distributions, err := internal.LoadDistributions(strings.Split(*dists, ","))
+	if err != nil {
+		logger.Fatal("failed to load the distributions", "error", err)
+	}
+	problems, err := internal.VerifyComponents(distributions)
+	if err != nil {
+		logger.Fatal("failed to verify the components", "error", err)
+	}
+	for _, p := range problems {
+		logger.Error("component "+p.Problem, "distribution", p.Distribution, "module", p.Module, "version", p.Version, "detail", p.Detail)
+	}
+	if len(problems) > 0 {
+		logger.Fatal("the component verification failed", "problems", len(problems))
+	}
+	logger.Info("every component resolves through the module proxy", "distributions", *dists)
+}
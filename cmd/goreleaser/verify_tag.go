@@ -0,0 +1,44 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+
+	"github.com/open-telemetry/opentelemetry-collector-releases/cmd/goreleaser/internal"
+)
+
+// verifyTag aborts unless the release tag is signed by an allowed maintainer.
+func verifyTag(args []string) {
+	fs := flag.NewFlagSet("verify-tag", flag.ExitOnError)
+	tag := fs.String("tag", "", "Release tag to verify")
+	gpgKeyring := fs.String("gpg-keyring", "", "File holding the armored GPG public keys allowed to sign release tags")
+	sshAllowedSigners := fs.String("ssh-allowed-signers", "", "ssh-keygen ALLOWED SIGNERS file listing the SSH keys allowed to sign release tags")
+	setupLogging := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLogging()
+
+	if len(*tag) == 0 {
+		logger.Fatal("no tag to verify")
+	}
+	err := internal.VerifyTag(*tag, internal.TagSigners{
+		GPGKeyring:        *gpgKeyring,
+		SSHAllowedSigners: *sshAllowedSigners,
+	})
+	if err != nil {
+		logger.Fatal("the tag verification failed", "tag", *tag, "error", err)
+	}
+	logger.Info("the tag is signed by an allowed maintainer", "tag", *tag)
+}
@@ -0,0 +1,45 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-releases/cmd/goreleaser/internal"
+)
+
+// verifyToolchain aborts unless the distributions would be built with the Go
+// toolchain they pin, or one at least as recent as their go.mod requires.
+// goreleaser runs it before every release.
+func verifyToolchain(args []string) {
+	fs := flag.NewFlagSet("verify-toolchain", flag.ExitOnError)
+	dists := fs.String("d", "", "Collector distributions(s) to verify, comma-separated")
+	setupLogging := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLogging()
+
+	if len(*dists) == 0 {
+		logger.Fatal("no distributions to verify")
+	}
+	distributions, err := internal.LoadDistributions(strings.Split(*dists, ","))
+	if err != nil {
+		logger.Fatal("failed to load the distributions", "error", err)
+	}
+	if err := internal.VerifyToolchains(distributions); err != nil {
+		logger.Fatal("the Go toolchain verification failed", "error", err)
+	}
+	logger.Info("the distributions are built with a Go toolchain matching their go.mod and pins", "distributions", *dists)
+}
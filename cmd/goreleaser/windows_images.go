@@ -0,0 +1,110 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-releases/cmd/goreleaser/internal"
+)
+
+// windowsImages builds the Windows images of the distributions opting in to a
+// Windows Server release with docker, which must run on a Windows host of
+// that release, from the windows/amd64 binaries of goreleaser's output
+// directory, and pushes them with -publish.
+func windowsImages(args []string) {
+	fs := flag.NewFlagSet("windows-images", flag.ExitOnError)
+	distsFlag := fs.String("d", "", "Collector distributions(s) to build the images of, comma-separated")
+	version := fs.String("windows-version", "", "Windows Server release of the host, one of "+strings.Join(internal.WindowsVersions, ", "))
+	distDir := fs.String("dist", "dist", "goreleaser's output directory, holding the windows/amd64 binaries")
+	prefixes := fs.String("image-prefixes", "", imagePrefixesUsage)
+	ecrPublic := fs.String("ecr-public-alias", "", "Also publish the images to Amazon ECR Public, under this registry alias")
+	publish := fs.Bool("publish", false, "Push the images to the registries")
+	setupLogging := logFlags(fs)
+	_ = fs.Parse(args)
+	setupLogging()
+
+	if len(*distsFlag) == 0 {
+		logger.Fatal("no distributions to build the images of")
+	}
+	dists, err := internal.LoadDistributions(strings.Split(*distsFlag, ","))
+	if err != nil {
+		logger.Fatal("failed to load the distributions", "error", err)
+	}
+	setImagePrefixes(*prefixes)
+	if *ecrPublic != "" {
+		internal.ImagePrefixes = append(internal.ImagePrefixes, path.Join(internal.ECRPublicRegistry, *ecrPublic))
+	}
+	if len(dists) == 0 {
+		return
+	}
+	commit, err := internal.HeadCommit(dists[0].Dist.Version)
+	if err != nil {
+		logger.Fatal("failed to read the release commit", "error", err)
+	}
+	images, err := internal.WindowsImages(internal.ImagePrefixes, dists, *version, commit)
+	if err != nil {
+		logger.Fatal("failed to configure the Windows images", "error", err)
+	}
+	if len(images) == 0 {
+		logger.Info("no distribution publishes Windows images for the release", "version", *version)
+		return
+	}
+
+	for _, image := range images {
+		binary, err := internal.WindowsBinary(*distDir, image.Dist)
+		if err != nil {
+			logger.Fatal("failed to find the Windows binary", "error", err)
+		}
+		// The build context holds the files goreleaser would put in it.
+		context, err := os.MkdirTemp("", "windows-image")
+		if err != nil {
+			logger.Fatal("failed to create the build context", "error", err)
+		}
+		files := map[string]string{filepath.Join(context, image.Dist.BinaryName()+".exe"): binary}
+		for _, file := range image.Files {
+			files[filepath.Join(context, filepath.FromSlash(file))] = file
+		}
+		for dest, src := range files {
+			b, err := os.ReadFile(src)
+			if err != nil {
+				logger.Fatal("failed to read a file of the build context", "file", src, "error", err)
+			}
+			if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+				logger.Fatal("failed to create the build context", "error", err)
+			}
+			if err := os.WriteFile(dest, b, 0o644); err != nil {
+				logger.Fatal("failed to write a file of the build context", "file", dest, "error", err)
+			}
+		}
+
+		buildArgs := append([]string{"build", "-f", image.Dockerfile}, image.BuildFlags...)
+		for _, tag := range image.Tags {
+			buildArgs = append(buildArgs, "-t", tag)
+		}
+		run("docker", append(buildArgs, context)...)
+		os.RemoveAll(context)
+		if *publish {
+			for _, tag := range image.Tags {
+				run("docker", "push", tag)
+			}
+		}
+		logger.Info("built the Windows image", "distribution", image.Dist.ID, "published", *publish, "tags", strings.Join(image.Tags, ","))
+	}
+}
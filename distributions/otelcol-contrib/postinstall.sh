@@ -14,6 +14,23 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+# /etc/otelcol-contrib/config.yaml points to the default config or to one of its variants.
+# Pick another one with `update-alternatives --config otelcol-contrib-config`.
+if command -v update-alternatives >/dev/null 2>&1; then
+    # Before the variants, config.yaml was a regular file, which
+    # update-alternatives refuses to replace with its link. It is kept as the
+    # preferred alternative, so that upgrades keep running the same config.
+    if [ -f /etc/otelcol-contrib/config.yaml ] && [ ! -L /etc/otelcol-contrib/config.yaml ]; then
+        mv /etc/otelcol-contrib/config.yaml /etc/otelcol-contrib/previous.yaml
+        update-alternatives --install /etc/otelcol-contrib/config.yaml otelcol-contrib-config /etc/otelcol-contrib/previous.yaml 40
+    fi
+    update-alternatives --install /etc/otelcol-contrib/config.yaml otelcol-contrib-config /etc/otelcol-contrib/default.yaml 30
+    update-alternatives --install /etc/otelcol-contrib/config.yaml otelcol-contrib-config /etc/otelcol-contrib/gateway.yaml 20
+    update-alternatives --install /etc/otelcol-contrib/config.yaml otelcol-contrib-config /etc/otelcol-contrib/agent.yaml 10
+elif [ ! -e /etc/otelcol-contrib/config.yaml ]; then
+    ln -s /etc/otelcol-contrib/default.yaml /etc/otelcol-contrib/config.yaml
+fi
+
 if command -v systemctl >/dev/null 2>&1; then
     systemctl enable otelcol-contrib.service
     if [ -f /etc/otelcol-contrib/config.yaml ]; then
@@ -18,3 +18,11 @@ if command -v systemctl >/dev/null 2>&1; then
     systemctl stop otelcol-contrib.service
     systemctl disable otelcol-contrib.service
 fi
+
+# Only drop the config alternatives when uninstalling, not when upgrading:
+# deb passes "remove" and rpm passes the number of remaining installs.
+if [ "$1" = "remove" ] || [ "$1" = "0" ]; then
+    if command -v update-alternatives >/dev/null 2>&1; then
+        update-alternatives --remove-all otelcol-contrib-config
+    fi
+fi
@@ -14,6 +14,23 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+# /etc/otelcol/config.yaml points to the default config or to one of its variants.
+# Pick another one with `update-alternatives --config otelcol-config`.
+if command -v update-alternatives >/dev/null 2>&1; then
+    # Before the variants, config.yaml was a regular file, which
+    # update-alternatives refuses to replace with its link. It is kept as the
+    # preferred alternative, so that upgrades keep running the same config.
+    if [ -f /etc/otelcol/config.yaml ] && [ ! -L /etc/otelcol/config.yaml ]; then
+        mv /etc/otelcol/config.yaml /etc/otelcol/previous.yaml
+        update-alternatives --install /etc/otelcol/config.yaml otelcol-config /etc/otelcol/previous.yaml 40
+    fi
+    update-alternatives --install /etc/otelcol/config.yaml otelcol-config /etc/otelcol/default.yaml 30
+    update-alternatives --install /etc/otelcol/config.yaml otelcol-config /etc/otelcol/gateway.yaml 20
+    update-alternatives --install /etc/otelcol/config.yaml otelcol-config /etc/otelcol/agent.yaml 10
+elif [ ! -e /etc/otelcol/config.yaml ]; then
+    ln -s /etc/otelcol/default.yaml /etc/otelcol/config.yaml
+fi
+
 if command -v systemctl >/dev/null 2>&1; then
     systemctl enable otelcol.service
     if [ -f /etc/otelcol/config.yaml ]; then
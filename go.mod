@@ -5,6 +5,7 @@ go 1.18
 require (
 	github.com/goreleaser/goreleaser v1.20.0
 	github.com/goreleaser/nfpm/v2 v2.34.0
+	golang.org/x/mod v0.12.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
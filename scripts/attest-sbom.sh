@@ -0,0 +1,21 @@
+#!/bin/bash
+
+# Catalogs a pushed image with syft and attaches the SPDX document to it as a
+# cosign attestation, signed keylessly like the images. goreleaser runs it for
+# each image it publishes.
+#
+# Usage: attest-sbom.sh image
+
+set -euo pipefail
+
+if [[ $# -ne 1 ]]; then
+    echo "No image to attest. Ex.:"
+    echo "$0 otel/opentelemetry-collector:0.89.0-amd64"
+    exit 1
+fi
+
+SBOM=$(mktemp)
+trap 'rm -f "$SBOM"' EXIT
+
+syft "$1" --output "spdx-json=${SBOM}"
+cosign attest --yes --type spdxjson --predicate "$SBOM" "$1"
@@ -33,6 +33,12 @@ fi
 
 echo "Distributions to build: $distributions";
 
+# Mismatched or retracted component versions have produced broken releases.
+if ! (cd "${REPO_DIR}" && "$GO" run ./cmd/goreleaser -d "${distributions}" -audit); then
+    echo "❌ ERROR: the components pinned by the distributions failed the audit."
+    exit 1
+fi
+
 for distribution in $(echo "$distributions" | tr "," "\n")
 do
     pushd "${REPO_DIR}/distributions/${distribution}" > /dev/null || exit
@@ -42,7 +48,11 @@ do
     echo "Using Builder: $(command -v "$BUILDER")"
     echo "Using Go: $(command -v "$GO")"
 
-    if "$BUILDER" --skip-compilation="${skipcompilation}" --go "$GO" --config manifest.yaml > _build/build.log 2>&1; then
+    # The sources are compiled separately, as the generator may need to add
+    # files to them, such as the embedded default config.
+    if "$BUILDER" --skip-compilation=true --go "$GO" --config manifest.yaml > _build/build.log 2>&1 \
+        && (cd "${REPO_DIR}" && "$GO" run ./cmd/goreleaser -d "${distribution}" -embed-config) >> _build/build.log 2>&1 \
+        && { [[ "$skipcompilation" = true ]] || (cd _build && "$GO" build -trimpath -ldflags="-s -w" -o "${distribution}" .) >> _build/build.log 2>&1; }; then
         echo "✅ SUCCESS: distribution '${distribution}' built."
     else
         echo "❌ ERROR: failed to build the distribution '${distribution}'."
@@ -53,5 +63,41 @@ do
         exit 1
     fi
 
+    # Build variants get their own manifest, derived from the distribution's.
+    variant_manifests=$(cd "${REPO_DIR}" && "$GO" run ./cmd/goreleaser -d "${distribution}" -variant-manifests) || exit 1
+    for variant_manifest in $variant_manifests
+    do
+        variant_dir=$(dirname "$variant_manifest")
+        variant="${distribution}-${variant_dir#_build-}"
+        if "$BUILDER" --skip-compilation="${skipcompilation}" --go "$GO" --config "$variant_manifest" > "${variant_dir}/build.log" 2>&1; then
+            echo "✅ SUCCESS: build variant '${variant}' built."
+        else
+            echo "❌ ERROR: failed to build the build variant '${variant}'."
+            echo "🪵 Build logs for '${variant}'"
+            echo "----------------------"
+            cat "${variant_dir}/build.log"
+            echo "----------------------"
+            exit 1
+        fi
+    done
+
+    # The release builds set the version reported by the collector.
+    if ! (cd "${REPO_DIR}" && "$GO" run ./cmd/goreleaser -d "${distribution}" -stamp-version); then
+        echo "❌ ERROR: failed to stamp the version of the distribution '${distribution}'."
+        exit 1
+    fi
+
+    # Image variants bundling auxiliary assets need them downloaded.
+    if ! (cd "${REPO_DIR}" && "$GO" run ./cmd/goreleaser -d "${distribution}" -image-assets); then
+        echo "❌ ERROR: failed to download the image assets of the distribution '${distribution}'."
+        exit 1
+    fi
+
+    # Distributions bundling the OpAMP supervisor need its sources.
+    if ! (cd "${REPO_DIR}" && "$GO" run ./cmd/goreleaser -d "${distribution}" -supervisors); then
+        echo "❌ ERROR: failed to prepare the OpAMP supervisor of the distribution '${distribution}'."
+        exit 1
+    fi
+
     popd > /dev/null || exit
 done
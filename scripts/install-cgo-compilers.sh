@@ -0,0 +1,43 @@
+#!/bin/bash
+
+# Installs the Ubuntu packages of the C cross-compilers that the cgo and FIPS
+# builds of a target use, as listed in CgoCompilers in
+# cmd/goreleaser/internal/cgo.go. The targets without one are skipped, as
+# well as linux/amd64, whose compiler is the host's. A target without an
+# architecture, such as "linux/" for a release split by GOOS, installs the
+# compilers of every architecture of the GOOS.
+#
+# Usage: install-cgo-compilers.sh goos/goarch
+
+set -euo pipefail
+
+if [[ $# -ne 1 ]]; then
+    echo "No target to install the C cross-compiler of. Ex.:"
+    echo "$0 linux/arm64"
+    exit 1
+fi
+
+declare -A PACKAGES=(
+    [linux/386]=gcc-i686-linux-gnu
+    [linux/arm]=gcc-arm-linux-gnueabihf
+    [linux/arm64]=gcc-aarch64-linux-gnu
+    [linux/ppc64le]=gcc-powerpc64le-linux-gnu
+    [linux/riscv64]=gcc-riscv64-linux-gnu
+    [linux/s390x]=gcc-s390x-linux-gnu
+    [windows/386]=gcc-mingw-w64-i686
+    [windows/amd64]=gcc-mingw-w64-x86-64
+)
+
+INSTALL=()
+for target in "${!PACKAGES[@]}"; do
+    if [[ "$target" == "$1" || ( "$1" == */ && "$target" == "$1"* ) ]]; then
+        INSTALL+=("${PACKAGES[$target]}")
+    fi
+done
+if [[ ${#INSTALL[@]} -eq 0 ]]; then
+    echo "No C cross-compiler to install for $1."
+    exit 0
+fi
+
+sudo apt-get update
+sudo apt-get install -y --no-install-recommends "${INSTALL[@]}"
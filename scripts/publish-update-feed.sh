@@ -0,0 +1,27 @@
+#!/bin/bash
+
+# Adds the release published by goreleaser to the update feed, committed to
+# the update-feed directory of the gh-pages branch, served by GitHub Pages.
+# The branch is created by the first release.
+#
+# Usage: publish-update-feed.sh tag
+
+set -euo pipefail
+
+if [[ $# -ne 1 ]]; then
+    echo "No tag of the release to add to the update feed. Ex.:"
+    echo "$0 v0.89.0"
+    exit 1
+fi
+
+if git fetch origin gh-pages; then
+    git worktree add --detach _gh-pages FETCH_HEAD
+else
+    git worktree add --detach _gh-pages
+    git -C _gh-pages checkout --orphan gh-pages
+    git -C _gh-pages rm -rfq .
+fi
+make generate-update-feed UPDATE_FEED_DIR=_gh-pages/update-feed
+git -C _gh-pages add update-feed
+git -C _gh-pages -c user.name="github-actions[bot]" -c user.email="github-actions[bot]@users.noreply.github.com" commit -m "Add $1 to the update feed"
+git -C _gh-pages push origin HEAD:gh-pages
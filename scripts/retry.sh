@@ -0,0 +1,50 @@
+#!/bin/bash
+
+# Runs a command until it succeeds, waiting exponentially longer between
+# attempts, and running a cleanup command before each new attempt. Meant for
+# the publishing steps of the release: registries skip the layers and
+# manifests they already have, but goreleaser fails on the assets already
+# uploaded to the GitHub release, so the cleanup deletes the partial release
+# before publishing it again.
+#
+# Usage: retry.sh [-a attempts] [-d initial delay in seconds] [-c cleanup] command...
+
+ATTEMPTS=5
+DELAY=30
+CLEANUP=""
+
+while getopts a:d:c: flag
+do
+    case "${flag}" in
+        a) ATTEMPTS=${OPTARG};;
+        d) DELAY=${OPTARG};;
+        c) CLEANUP=${OPTARG};;
+        *) exit 1;;
+    esac
+done
+shift $((OPTIND - 1))
+
+if [[ $# -eq 0 ]]; then
+    echo "No command to run. Ex.:"
+    echo "$0 -a 5 -d 30 -c 'gh release delete v0.89.0 --yes' goreleaser continue --merge"
+    exit 1
+fi
+
+for attempt in $(seq 1 "$ATTEMPTS")
+do
+    if "$@"; then
+        exit 0
+    fi
+    if [[ "$attempt" -lt "$ATTEMPTS" ]]; then
+        echo "⚠️ Attempt ${attempt}/${ATTEMPTS} failed, retrying in ${DELAY}s."
+        sleep "$DELAY"
+        DELAY=$((DELAY * 2))
+        if [[ -n "$CLEANUP" ]] && ! bash -c "$CLEANUP"; then
+            echo "❌ ERROR: cleanup failed: ${CLEANUP}"
+            exit 1
+        fi
+    fi
+done
+
+echo "❌ ERROR: command failed after ${ATTEMPTS} attempts: $*"
+exit 1
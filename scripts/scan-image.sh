@@ -0,0 +1,40 @@
+#!/bin/bash
+
+# Scans a locally built image for vulnerabilities with trivy, or grype when
+# trivy isn't installed, and fails when one of the given severity or above
+# has a fix available. goreleaser runs it for each image it builds, before
+# anything is published, so that vulnerable images never reach the
+# registries.
+#
+# Usage: scan-image.sh severity image
+
+set -euo pipefail
+
+if [[ $# -ne 2 ]]; then
+    echo "No severity nor image to scan. Ex.:"
+    echo "$0 critical otel/opentelemetry-collector:0.89.0-amd64"
+    exit 1
+fi
+
+SEVERITY=${1,,}
+IMAGE=$2
+
+if command -v trivy > /dev/null; then
+    # trivy takes the list of the severities failing the scan.
+    SEVERITIES=""
+    for s in LOW MEDIUM HIGH CRITICAL; do
+        if [[ -n "$SEVERITIES" || "$s" == "${SEVERITY^^}" ]]; then
+            SEVERITIES="${SEVERITIES:+${SEVERITIES},}${s}"
+        fi
+    done
+    if [[ -z "$SEVERITIES" ]]; then
+        echo "Unknown severity ${SEVERITY}, expected low, medium, high or critical."
+        exit 1
+    fi
+    trivy image --no-progress --exit-code 1 --ignore-unfixed --severity "$SEVERITIES" "$IMAGE"
+elif command -v grype > /dev/null; then
+    grype --only-fixed --fail-on "$SEVERITY" "docker:${IMAGE}"
+else
+    echo "Neither trivy nor grype is installed to scan ${IMAGE}."
+    exit 1
+fi